@@ -0,0 +1,214 @@
+package algorithms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+)
+
+// encoding/json output, in struct declaration order. this is the default, and what every record
+// written before canonicalization was configurable was hashed and signed over.
+type GoJSON struct{}
+
+func (GoJSON) Canonicalize(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// the JSON Canonicalization Scheme (RFC 8785). the value is encoded with encoding/json, so tags and
+// custom marshalers still apply, then members are sorted by key and strings and numbers are
+// re-serialised the way ECMAScript would, so other languages can reproduce the bytes exactly.
+type JCS struct{}
+
+func (JCS) Canonicalize(v any) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := writeCanonical(buffer, value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// helpers
+
+func canonicalizerOrDefault(canonicalizer interfaces.Canonicalizer) interfaces.Canonicalizer {
+	if canonicalizer == nil {
+		return GoJSON{}
+	}
+
+	return canonicalizer
+}
+
+func writeCanonical(buffer *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buffer, v)
+	case json.Number:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteString(number)
+	case []any:
+		buffer.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			if err := writeCanonical(buffer, element); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		// members are ordered by the UTF-16 code units of their keys
+		slices.SortFunc(keys, func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			writeCanonicalString(buffer, key)
+			buffer.WriteByte(':')
+
+			if err := writeCanonical(buffer, v[key]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot canonicalize %T", value)
+	}
+
+	return nil
+}
+
+// only quotes, backslashes and control characters are escaped
+func writeCanonicalString(buffer *bytes.Buffer, s string) {
+	buffer.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, r)
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+
+	buffer.WriteByte('"')
+}
+
+// formats a number as ECMAScript's Number.prototype.toString would, from its shortest round trip
+// representation as an IEEE 754 double
+func canonicalNumber(number json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(number), 64)
+	if err != nil {
+		return "", err
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("cannot canonicalize %s", number)
+	}
+
+	if f == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// d.ddde±x gives the significant digits and the decimal exponent
+	mantissa, exponentString, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+
+	exponent, err := strconv.Atoi(exponentString)
+	if err != nil {
+		return "", err
+	}
+
+	k := len(digits)
+	n := exponent + 1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+
+	exponentSign := "+"
+	if n-1 < 0 {
+		exponentSign = "-"
+	}
+
+	significand := digits[:1]
+	if k > 1 {
+		significand += "." + digits[1:]
+	}
+
+	return fmt.Sprintf("%s%se%s%d", sign, significand, exponentSign, abs(n-1)), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,61 @@
+package algorithms_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+type unordered struct {
+	primitives.Prefixer
+	Zebra string `json:"zebra"`
+	Apple string `json:"apple"`
+}
+
+func TestJCS(t *testing.T) {
+	if err := testJCS(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testJCS() error {
+	// from RFC 8785 and its test data
+	vectors := map[string]string{
+		`{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}`: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		`[56,{"d":true,"10":null,"1":[]}]`:               `[56,{"1":[],"10":null,"d":true}]`,
+		`[-0,1e20,1e21,1e-6,1e-7,-1.5,9007199254740993]`: `[0,100000000000000000000,1e+21,0.000001,1e-7,-1.5,9007199254740992]`,
+		`{"ﬁ":1,"😀":2,"€":3,"é":4,"a":5}`:                `{"a":5,"é":4,"€":3,"😀":2,"ﬁ":1}`,
+		`"<tag> &  "`: "\"<tag> &  \"",
+	}
+
+	for input, expected := range vectors {
+		canonical, err := algorithms.JCS{}.Canonicalize(json.RawMessage(input))
+		if err != nil {
+			return err
+		}
+
+		if string(canonical) != expected {
+			return fmt.Errorf("unexpected canonicalization of %s: %s", input, canonical)
+		}
+	}
+
+	// self-addresses depend on the canonicalization, not the declaration order
+	prefixer := &unordered{Zebra: "z", Apple: "a"}
+	if err := algorithms.CreatePrefix(prefixer, nil, algorithms.JCS{}); err != nil {
+		return err
+	}
+
+	if err := algorithms.VerifyPrefixAndData(prefixer, algorithms.JCS{}); err != nil {
+		return err
+	}
+
+	if err := algorithms.VerifyPrefixAndData(prefixer, nil); err == nil {
+		return fmt.Errorf("unexpected verification with the wrong canonicalizer")
+	}
+
+	return nil
+}
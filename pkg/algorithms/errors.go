@@ -0,0 +1,120 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+)
+
+var (
+	ErrTamperDetected   = errors.New("tamper detected")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnknownSigner    = errors.New("unknown signer")
+	ErrThresholdNotMet  = errors.New("signing threshold not met")
+	ErrKeyNotValid      = errors.New("key not valid")
+
+	// an id's derivation code names a hash algorithm the verifier doesn't accept
+	ErrUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+)
+
+// field is the self-describing field that failed to verify (id or prefix). err is set when the
+// field could not even be decoded.
+type TamperError struct {
+	Id    string
+	Field string
+	Err   error
+}
+
+func (e TamperError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s verification failed for %s: %s", ErrTamperDetected, e.Field, e.Id, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s verification failed for %s", ErrTamperDetected, e.Field, e.Id)
+}
+
+func (e TamperError) Is(target error) bool {
+	return target == ErrTamperDetected
+}
+
+func (e TamperError) Unwrap() error {
+	return e.Err
+}
+
+type SignatureError struct {
+	Identity string
+	Err      error
+}
+
+func (e SignatureError) Error() string {
+	return fmt.Sprintf("%s from %s: %s", ErrInvalidSignature, e.Identity, e.Err)
+}
+
+func (e SignatureError) Is(target error) bool {
+	return target == ErrInvalidSignature
+}
+
+func (e SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// the record was created outside the window in which the key could sign (createdAt is nil for a
+// record without a timestamp). err is set when the window could not be looked up.
+type KeyValidityError struct {
+	Identity  string
+	CreatedAt *time.Time
+	Validity  interfaces.KeyValidity
+	Err       error
+}
+
+func (e KeyValidityError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s for %s: %s", ErrKeyNotValid, e.Identity, e.Err)
+	case e.CreatedAt == nil:
+		return fmt.Sprintf("%s for %s: record has no timestamp", ErrKeyNotValid, e.Identity)
+	default:
+		return fmt.Sprintf("%s for %s at %s", ErrKeyNotValid, e.Identity, e.CreatedAt.Format(time.RFC3339Nano))
+	}
+}
+
+func (e KeyValidityError) Is(target error) bool {
+	return target == ErrKeyNotValid
+}
+
+func (e KeyValidityError) Unwrap() error {
+	return e.Err
+}
+
+type UnknownSignerError struct {
+	Identity string
+	Err      error
+}
+
+func (e UnknownSignerError) Error() string {
+	return fmt.Sprintf("%s %s: %s", ErrUnknownSigner, e.Identity, e.Err)
+}
+
+func (e UnknownSignerError) Is(target error) bool {
+	return target == ErrUnknownSigner
+}
+
+func (e UnknownSignerError) Unwrap() error {
+	return e.Err
+}
+
+// every signature was valid, but the identities in signers don't carry enough weight
+type ThresholdError struct {
+	Signers []string
+}
+
+func (e ThresholdError) Error() string {
+	return fmt.Sprintf("%s: signed by %s", ErrThresholdNotMet, strings.Join(e.Signers, ", "))
+}
+
+func (e ThresholdError) Is(target error) bool {
+	return target == ErrThresholdNotMet
+}
@@ -0,0 +1,73 @@
+package algorithms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/zeebo/blake3"
+)
+
+// commits to message without revealing it, using hasher (blake3 when nil)
+func Digest(message string, hasher interfaces.Hasher) string {
+	return hasherOrDefault(hasher).Sum(message)
+}
+
+// recomputes digest over message with the hasher its derivation code names, which must be blake3 or
+// one of hashers
+func DigestMatches(digest, message string, hashers ...interfaces.Hasher) (bool, error) {
+	hasher, err := hasherFor(digest, hashers)
+	if err != nil {
+		return false, err
+	}
+
+	return hasher.Sum(message) == digest, nil
+}
+
+// helpers
+
+func hasherOrDefault(hasher interfaces.Hasher) interfaces.Hasher {
+	if hasher == nil {
+		return blake3Hasher{}
+	}
+
+	return hasher
+}
+
+// an id without a readable code can't have been produced by any hasher, so it has been tampered with.
+// blake3 is always accepted, alongside whichever hashers the caller accepts.
+func hasherFor(id string, hashers []interfaces.Hasher) (interfaces.Hasher, error) {
+	code, err := cesr.ReadCode(id)
+	if err != nil {
+		return nil, TamperError{Id: id, Field: "id", Err: err}
+	}
+
+	for _, hasher := range hashers {
+		if hasher.Code() == code {
+			return hasher, nil
+		}
+	}
+
+	if code == cesr.Blake3_256 {
+		return blake3Hasher{}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownHashAlgorithm, id)
+}
+
+// stands in for the id (and prefix) while the address is computed, matching the digest's length
+func placeholder(hasher interfaces.Hasher) string {
+	return strings.Repeat("#", len(hasher.Sum("")))
+}
+
+type blake3Hasher struct{}
+
+func (blake3Hasher) Code() string {
+	return cesr.Blake3_256
+}
+
+func (blake3Hasher) Sum(message string) string {
+	sum := blake3.Sum256([]byte(message))
+	return cesr.MustDigest(cesr.Blake3_256, sum[:])
+}
@@ -0,0 +1,87 @@
+package algorithms_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+func TestHashers(t *testing.T) {
+	if err := testHashers(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testHashers() error {
+	// the default and the blake3 example must agree
+	defaulted := &primitives.Prefixer{}
+	if err := algorithms.CreatePrefix(defaulted, nil, nil); err != nil {
+		return err
+	}
+
+	explicit := &primitives.Prefixer{}
+	if err := algorithms.CreatePrefix(explicit, examples.NewBlake3(), nil); err != nil {
+		return err
+	}
+
+	if defaulted.Id != explicit.Id {
+		return fmt.Errorf("default and blake3 ids differ: %s, %s", defaulted.Id, explicit.Id)
+	}
+
+	// rfc 7693 with a 32 byte digest, checked against python's hashlib.blake2b
+	vectors := map[string]string{
+		"":                       "FA5XUcAm5UOy6KsusGCZ2qHR5d9Hd493h_qrRc3xL-Oo",
+		"abc":                    "FL3dgTxjQjlyMXHvP-6YV5uUlk47scs-QnJiyMBo1SMZ",
+		strings.Repeat("x", 128): "FBZP-3CJuub1pi-weV51HcnojqyS4aWy-v6Tolq_LZw7",
+		strings.Repeat("y", 129): "FB-uAGDeQEonwqXLVmjCS3J3zFHd0sPa6IuKgkqBGTpJ",
+		strings.Repeat("z", 300): "FOHlMNOm3TPJm9vCQI0niySbPpvdVqFlWFNIEwaLwtb6",
+	}
+
+	for message, expected := range vectors {
+		if digest := examples.NewBlake2b_256().Sum(message); digest != expected {
+			return fmt.Errorf("unexpected blake2b digest of %d bytes: %s", len(message), digest)
+		}
+	}
+
+	for _, hasher := range []interfaces.Hasher{examples.NewSha256(), examples.NewSha3_256(), examples.NewBlake2b_256()} {
+		prefixer := &primitives.Prefixer{}
+		if err := algorithms.CreatePrefix(prefixer, hasher, nil); err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(prefixer.Id, hasher.Code()) || len(prefixer.Id) != 44 {
+			return fmt.Errorf("unexpected id for code %s: %s", hasher.Code(), prefixer.Id)
+		}
+
+		if err := algorithms.VerifyPrefixAndData(prefixer, nil); !errors.Is(err, algorithms.ErrUnknownHashAlgorithm) {
+			return fmt.Errorf("expected an unknown algorithm unless accepted: %v", err)
+		}
+
+		if err := algorithms.VerifyPrefixAndData(prefixer, nil, hasher); err != nil {
+			return err
+		}
+
+		addresser := &primitives.SelfAddresser{}
+		if err := algorithms.SelfAddress(addresser, hasher, nil); err != nil {
+			return err
+		}
+
+		if err := algorithms.VerifyAddressAndData(addresser, nil, hasher); err != nil {
+			return err
+		}
+
+		addresser.Id = hasher.Code() + addresser.Id[2:] + "A"
+		if err := algorithms.VerifyAddressAndData(addresser, nil, hasher); !errors.Is(err, algorithms.ErrTamperDetected) {
+			return fmt.Errorf("expected tamper detection: %v", err)
+		}
+	}
+
+	return nil
+}
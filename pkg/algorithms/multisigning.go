@@ -0,0 +1,141 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+// identities are set before the callback runs, and so are covered by every signature. each key
+// signs once, indexed by the position of its identity in identities. a nil canonicalizer uses the
+// default (GoJSON).
+func MultiSign(
+	s primitives.MultiSignable,
+	identities []string,
+	keys []interfaces.SigningKey,
+	canonicalizer interfaces.Canonicalizer,
+	callback func() error,
+) error {
+	indexes := []int{}
+	for _, key := range keys {
+		identity, err := key.Identity()
+		if err != nil {
+			return err
+		}
+
+		index := slices.Index(identities, identity)
+		if index < 0 {
+			return fmt.Errorf("%s is not one of the signing identities", identity)
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	s.SetSigningIdentities(slices.Clone(identities))
+
+	if err := callback(); err != nil {
+		return err
+	}
+
+	message, err := canonicalizerOrDefault(canonicalizer).Canonicalize(s)
+	if err != nil {
+		return err
+	}
+
+	signatures := make([]string, len(identities))
+	for i, key := range keys {
+		signature, err := key.Sign(message)
+		if err != nil {
+			return err
+		}
+
+		siger, err := cesr.ParseSiger(signature)
+		if err != nil {
+			return err
+		}
+
+		indexer, err := cesr.NewIndexer(siger, uint(indexes[i]))
+		if err != nil {
+			return err
+		}
+
+		signatures[indexes[i]] = indexer.Qb64()
+	}
+
+	// in index order, without the identities that didn't sign
+	s.SetSignatures(slices.DeleteFunc(signatures, func(signature string) bool { return signature == "" }))
+
+	return nil
+}
+
+// every signature must verify against the identity it is indexed to, and the identities that
+// signed must then meet the threshold. the threshold, not the record, decides whose signatures
+// count and by how much.
+func VerifySignatures(
+	s primitives.MultiSignable,
+	threshold Threshold,
+	verificationKeyStore interfaces.VerificationKeyStore,
+	canonicalizer interfaces.Canonicalizer,
+) error {
+	return VerifySignaturesContext(context.Background(), s, threshold, verificationKeyStore, canonicalizer)
+}
+
+// ctx is passed to key stores that look keys up in the database (interfaces.HistoricalVerificationKeyStore)
+func VerifySignaturesContext(
+	ctx context.Context,
+	s primitives.MultiSignable,
+	threshold Threshold,
+	verificationKeyStore interfaces.VerificationKeyStore,
+	canonicalizer interfaces.Canonicalizer,
+) error {
+	identities := s.GetSigningIdentities()
+	for i, identity := range identities {
+		if slices.Contains(identities[:i], identity) {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidSignature, identity)
+		}
+	}
+
+	message, err := canonicalizerOrDefault(canonicalizer).Canonicalize(s)
+	if err != nil {
+		return err
+	}
+
+	created := primitives.CreatedAt(s)
+	signers := []string{}
+	for _, signature := range s.GetSignatures() {
+		indexer, err := cesr.ParseIndexer(signature)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+
+		if indexer.Index() >= uint(len(identities)) {
+			return fmt.Errorf("%w: index %d is out of range", ErrInvalidSignature, indexer.Index())
+		}
+
+		identity := identities[indexer.Index()]
+		if slices.Contains(signers, identity) {
+			return fmt.Errorf("%w: %s signed more than once", ErrInvalidSignature, identity)
+		}
+
+		siger, err := indexer.Siger()
+		if err != nil {
+			return SignatureError{Identity: identity, Err: err}
+		}
+
+		if err := verifySiger(ctx, siger.Qb64(), identity, message, created, verificationKeyStore); err != nil {
+			return err
+		}
+
+		signers = append(signers, identity)
+	}
+
+	if !threshold.Satisfied(signers) {
+		return ThresholdError{Signers: signers}
+	}
+
+	return nil
+}
@@ -1,16 +1,19 @@
 package algorithms
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
 )
 
-func CreatePrefix(p primitives.Prefixable) error {
-	p.SetPrefix("############################################")
+// as with SelfAddress, nil selects the default hasher and canonicalizer
+func CreatePrefix(p primitives.Prefixable, hasher interfaces.Hasher, canonicalizer interfaces.Canonicalizer) error {
+	hasher = hasherOrDefault(hasher)
 
-	if err := SelfAddress(p); err != nil {
+	p.SetPrefix(placeholder(hasher))
+
+	if err := SelfAddress(p, hasher, canonicalizer); err != nil {
 		return err
 	}
 
@@ -19,27 +22,33 @@ func CreatePrefix(p primitives.Prefixable) error {
 	return nil
 }
 
-func VerifyPrefixAndData(p primitives.Prefixable) error {
-	oldId := p.GetId()
-	oldPrefix := p.GetPrefix()
+// like VerifyAddressAndData, the hasher is chosen by the id's code and p is never modified
+func VerifyPrefixAndData(p primitives.Prefixable, canonicalizer interfaces.Canonicalizer, hashers ...interfaces.Hasher) error {
+	hasher, err := hasherFor(p.GetId(), hashers)
+	if err != nil {
+		return err
+	}
 
-	defer func() {
-		p.SetId(oldId)
-		p.SetPrefix(oldPrefix)
-	}()
+	duplicate, err := copyOf(p)
+	if err != nil {
+		return err
+	}
+
+	duplicate.SetPrefix(placeholder(hasher))
+	duplicate.SetId(placeholder(hasher))
 
-	if err := CreatePrefix(p); err != nil {
+	id, err := address(duplicate, hasher, canonicalizer)
+	if err != nil {
 		return err
 	}
 
-	if !strings.EqualFold(p.GetId(), oldId) {
-		return fmt.Errorf("address verification failed")
+	if !strings.EqualFold(id, p.GetId()) {
+		return TamperError{Id: p.GetId(), Field: "id"}
 	}
 
-	if !strings.EqualFold(p.GetId(), oldPrefix) {
-		return fmt.Errorf("prefix verification failed")
+	if !strings.EqualFold(id, p.GetPrefix()) {
+		return TamperError{Id: p.GetId(), Field: "prefix"}
 	}
 
 	return nil
-
 }
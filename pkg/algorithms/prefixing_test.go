@@ -1,6 +1,7 @@
 package algorithms_test
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -19,7 +20,7 @@ func TestPrefixing(t *testing.T) {
 func testPrefixing() error {
 	prefixer := &primitives.Prefixer{}
 
-	if err := algorithms.CreatePrefix(prefixer); err != nil {
+	if err := algorithms.CreatePrefix(prefixer, nil, nil); err != nil {
 		return err
 	}
 
@@ -34,22 +35,28 @@ func testPrefixing() error {
 		return fmt.Errorf("unexpected prefix: %s", prefixer.Prefix)
 	}
 
-	if err := algorithms.VerifyPrefixAndData(prefixer); err != nil {
+	if err := algorithms.VerifyPrefixAndData(prefixer, nil); err != nil {
 		return err
 	}
 
 	prefixer.Id = badPrefix
 
-	if err := algorithms.VerifyPrefixAndData(prefixer); err == nil {
+	if err := algorithms.VerifyPrefixAndData(prefixer, nil); err == nil {
 		return fmt.Errorf("unexpected verification success with bad id")
 	}
 
 	prefixer.Id = expectedPrefix
 	prefixer.Prefix = badPrefix
 
-	if err := algorithms.VerifyPrefixAndData(prefixer); err == nil {
+	err := algorithms.VerifyPrefixAndData(prefixer, nil)
+	if err == nil {
 		return fmt.Errorf("unexpected verification success with bad prefix")
 	}
 
+	var tamperErr algorithms.TamperError
+	if !errors.As(err, &tamperErr) || tamperErr.Field != "prefix" {
+		return fmt.Errorf("unexpected error: %s", err)
+	}
+
 	return nil
 }
@@ -1,50 +1,78 @@
 package algorithms
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
-	"github.com/zeebo/blake3"
 )
 
-func SelfAddress(s primitives.SelfAddressable) error {
-	s.SetId("############################################")
+// a nil hasher uses the default (blake3), and a nil canonicalizer the default (GoJSON)
+func SelfAddress(s primitives.SelfAddressable, hasher interfaces.Hasher, canonicalizer interfaces.Canonicalizer) error {
+	hasher = hasherOrDefault(hasher)
 
-	message, err := json.Marshal(s)
+	s.SetId(placeholder(hasher))
+
+	id, err := address(s, hasher, canonicalizer)
 	if err != nil {
 		return err
 	}
 
-	buffer := [33]byte{}
-	sum := blake3.Sum256(message)
-	copy(buffer[1:], sum[:])
-
-	b64 := base64.URLEncoding.EncodeToString(buffer[:])
-	qb64 := []rune(b64)
-	qb64[0] = 'E'
-
-	s.SetId(string(qb64))
+	s.SetId(id)
 
 	return nil
 }
 
-func VerifyAddressAndData(s primitives.SelfAddressable) error {
-	oldId := s.GetId()
+// the hasher is chosen by the id's derivation code from blake3 and hashers, but the canonicalizer
+// must match the one the record was addressed with. the address is computed from a copy, so s is
+// never modified and may be shared with other readers.
+func VerifyAddressAndData(s primitives.SelfAddressable, canonicalizer interfaces.Canonicalizer, hashers ...interfaces.Hasher) error {
+	hasher, err := hasherFor(s.GetId(), hashers)
+	if err != nil {
+		return err
+	}
 
-	defer func() {
-		s.SetId(oldId)
-	}()
+	duplicate, err := copyOf(s)
+	if err != nil {
+		return err
+	}
 
-	if err := SelfAddress(s); err != nil {
+	duplicate.SetId(placeholder(hasher))
+
+	id, err := address(duplicate, hasher, canonicalizer)
+	if err != nil {
 		return err
 	}
 
-	if !strings.EqualFold(s.GetId(), oldId) {
-		return fmt.Errorf("address verification failed")
+	if !strings.EqualFold(id, s.GetId()) {
+		return TamperError{Id: s.GetId(), Field: "id"}
 	}
 
 	return nil
 }
+
+func address(s primitives.SelfAddressable, hasher interfaces.Hasher, canonicalizer interfaces.Canonicalizer) (string, error) {
+	message, err := canonicalizerOrDefault(canonicalizer).Canonicalize(s)
+	if err != nil {
+		return "", err
+	}
+
+	return hasher.Sum(string(message)), nil
+}
+
+// a shallow copy is enough, since only the id and prefix strings are replaced
+func copyOf[T any](v T) (T, error) {
+	var zero T
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return zero, fmt.Errorf("cannot copy %T: expected a non-nil pointer", v)
+	}
+
+	duplicate := reflect.New(value.Elem().Type())
+	duplicate.Elem().Set(value.Elem())
+
+	return duplicate.Interface().(T), nil
+}
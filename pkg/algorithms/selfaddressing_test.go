@@ -1,8 +1,10 @@
 package algorithms_test
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
@@ -19,7 +21,7 @@ func TestSelfAddressing(t *testing.T) {
 func testSelfAddressing() error {
 	addresser := &primitives.SelfAddresser{}
 
-	if err := algorithms.SelfAddress(addresser); err != nil {
+	if err := algorithms.SelfAddress(addresser, nil, nil); err != nil {
 		return err
 	}
 
@@ -30,15 +32,74 @@ func testSelfAddressing() error {
 		return fmt.Errorf("unexpected id: %s", addresser.Id)
 	}
 
-	if err := algorithms.VerifyAddressAndData(addresser); err != nil {
+	if err := algorithms.VerifyAddressAndData(addresser, nil); err != nil {
 		return err
 	}
 
 	addresser.Id = badId
 
-	if err := algorithms.VerifyAddressAndData(addresser); err == nil {
+	err := algorithms.VerifyAddressAndData(addresser, nil)
+	if err == nil {
 		return fmt.Errorf("unexpected verification success with bad id")
 	}
 
+	var tamperErr algorithms.TamperError
+	if !errors.As(err, &tamperErr) || !errors.Is(err, algorithms.ErrTamperDetected) {
+		return fmt.Errorf("unexpected error type: %T", err)
+	}
+
+	if tamperErr.Id != badId || tamperErr.Field != "id" {
+		return fmt.Errorf("unexpected tamper error: %s", tamperErr)
+	}
+
+	return nil
+}
+
+func TestVerificationDoesNotMutate(t *testing.T) {
+	if err := testVerificationDoesNotMutate(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testVerificationDoesNotMutate() error {
+	prefixer := &primitives.Prefixer{}
+	if err := algorithms.CreatePrefix(prefixer, nil, nil); err != nil {
+		return err
+	}
+
+	id := prefixer.Id
+
+	// concurrent readers and verifiers of a shared record (run with -race)
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 8 {
+		wg.Go(func() {
+			if err := algorithms.VerifyPrefixAndData(prefixer, nil); err != nil {
+				errs <- err
+			}
+		})
+		wg.Go(func() {
+			if prefixer.GetId() != id || prefixer.GetPrefix() != id {
+				errs <- fmt.Errorf("observed a modified record")
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		return err
+	}
+
+	prefixer.Prefix = "Etampered"
+	if err := algorithms.VerifyPrefixAndData(prefixer, nil); err == nil {
+		return fmt.Errorf("unexpected verification success with bad prefix")
+	}
+
+	if prefixer.Id != id || prefixer.Prefix != "Etampered" {
+		return fmt.Errorf("failed verification modified the record: %s, %s", prefixer.Id, prefixer.Prefix)
+	}
+
 	return nil
 }
@@ -1,13 +1,24 @@
 package algorithms
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
 )
 
-func Sign(s primitives.Signable, key interfaces.SigningKey, callback func() error) error {
+// a nil canonicalizer uses the default (GoJSON)
+func Sign(
+	s primitives.Signable,
+	key interfaces.SigningKey,
+	canonicalizer interfaces.Canonicalizer,
+	callback func() error,
+) error {
 	identity, err := key.Identity()
 	if err != nil {
 		return err
@@ -19,7 +30,7 @@ func Sign(s primitives.Signable, key interfaces.SigningKey, callback func() erro
 		return err
 	}
 
-	message, err := json.Marshal(s)
+	message, err := canonicalizerOrDefault(canonicalizer).Canonicalize(s)
 	if err != nil {
 		return err
 	}
@@ -34,23 +45,145 @@ func Sign(s primitives.Signable, key interfaces.SigningKey, callback func() erro
 	return nil
 }
 
-func VerifySignature(s primitives.Signable, verificationKeyStore interfaces.VerificationKeyStore) error {
-	verificationKey, err := verificationKeyStore.Get(s.GetSigningIdentity())
+// the key must be of the type that produces the signature's code. a key store that dispatches by
+// code (interfaces.DispatchingVerificationKeyStore) picks the verifier, otherwise the key's own is
+// used, so a single key store can hold keys of any mix of algorithms.
+func VerifySignature(
+	s primitives.Signable,
+	verificationKeyStore interfaces.VerificationKeyStore,
+	canonicalizer interfaces.Canonicalizer,
+) error {
+	return VerifySignatureContext(context.Background(), s, verificationKeyStore, canonicalizer)
+}
+
+// ctx is passed to key stores that look keys up in the database (interfaces.HistoricalVerificationKeyStore)
+func VerifySignatureContext(
+	ctx context.Context,
+	s primitives.Signable,
+	verificationKeyStore interfaces.VerificationKeyStore,
+	canonicalizer interfaces.Canonicalizer,
+) error {
+	message, err := canonicalizerOrDefault(canonicalizer).Canonicalize(s)
 	if err != nil {
 		return err
 	}
 
-	verificationPublicKey, err := verificationKey.Public()
+	return verifySiger(ctx, s.GetSignature(), s.GetSigningIdentity(), message, primitives.CreatedAt(s), verificationKeyStore)
+}
+
+func verifySiger(
+	ctx context.Context,
+	signature string,
+	identity string,
+	message []byte,
+	createdAt *time.Time,
+	verificationKeyStore interfaces.VerificationKeyStore,
+) error {
+	verificationKey, err := getVerificationKey(ctx, identity, createdAt, verificationKeyStore)
 	if err != nil {
 		return err
 	}
 
-	message, err := json.Marshal(s)
+	if err := checkValidity(identity, createdAt, verificationKeyStore); err != nil {
+		return err
+	}
+
+	verificationPublicKey, err := verificationKey.Public()
 	if err != nil {
 		return err
 	}
 
-	return verificationKey.Verifier().Verify(s.GetSignature(), verificationPublicKey, message)
+	verifier, err := verifierFor(signature, verificationPublicKey, verificationKey, verificationKeyStore)
+	if err != nil {
+		return SignatureError{Identity: identity, Err: err}
+	}
+
+	if err := verifier.Verify(signature, verificationPublicKey, message); err != nil {
+		return SignatureError{Identity: identity, Err: err}
+	}
+
+	return nil
+}
+
+func getVerificationKey(
+	ctx context.Context,
+	identity string,
+	createdAt *time.Time,
+	verificationKeyStore interfaces.VerificationKeyStore,
+) (interfaces.VerificationKey, error) {
+	historical, ok := verificationKeyStore.(interfaces.HistoricalVerificationKeyStore)
+	if !ok {
+		verificationKey, err := verificationKeyStore.Get(identity)
+		if err != nil {
+			return nil, UnknownSignerError{Identity: identity, Err: err}
+		}
+
+		return verificationKey, nil
+	}
+
+	verificationKey, err := historical.GetAt(ctx, identity, createdAt)
+	if err != nil {
+		// the identity is known, but held no key when the record was created
+		if errors.Is(err, ErrKeyNotValid) {
+			return nil, err
+		}
+
+		return nil, UnknownSignerError{Identity: identity, Err: err}
+	}
+
+	return verificationKey, nil
+}
+
+// fails closed: a bounded window can't be checked against a record without a timestamp
+func checkValidity(identity string, createdAt *time.Time, verificationKeyStore interfaces.VerificationKeyStore) error {
+	timed, ok := verificationKeyStore.(interfaces.TimedVerificationKeyStore)
+	if !ok {
+		return nil
+	}
+
+	validity, err := timed.Validity(identity)
+	if err != nil {
+		return KeyValidityError{Identity: identity, CreatedAt: createdAt, Err: err}
+	}
+
+	if !validity.Bounded() {
+		return nil
+	}
+
+	if createdAt == nil || !validity.Contains(*createdAt) {
+		return KeyValidityError{Identity: identity, CreatedAt: createdAt, Validity: validity}
+	}
+
+	return nil
+}
+
+func verifierFor(
+	signature string,
+	publicKey string,
+	verificationKey interfaces.VerificationKey,
+	verificationKeyStore interfaces.VerificationKeyStore,
+) (interfaces.Verifier, error) {
+	signatureCode, err := cesr.ReadCode(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	verfer, err := cesr.ParseVerfer(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if verfer.SignatureCode() != signatureCode {
+		return nil, fmt.Errorf("%w: %s keys do not produce %s signatures", cesr.ErrUnexpectedCode, verfer.Code(), signatureCode)
+	}
+
+	if dispatching, ok := verificationKeyStore.(interfaces.DispatchingVerificationKeyStore); ok {
+		if verifier, found := dispatching.Verifier(signatureCode); found {
+			return verifier, nil
+		}
+	}
+
+	return verificationKey.Verifier(), nil
 }
 
 func CreateSignedContainer[T primitives.Signable](record T) (string, error) {
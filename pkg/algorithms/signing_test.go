@@ -1,11 +1,16 @@
 package algorithms_test
 
 import (
+	"crypto/elliptic"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
 )
@@ -35,7 +40,7 @@ func testSigning() error {
 	keyStore := examples.NewVerificationKeyStore()
 	keyStore.Add(identity, key)
 
-	if err := algorithms.Sign(signer, key, func() error { return nil }); err != nil {
+	if err := algorithms.Sign(signer, key, nil, func() error { return nil }); err != nil {
 		return err
 	}
 
@@ -53,22 +58,194 @@ func testSigning() error {
 		return fmt.Errorf("unexpected signature: %s", signer.Signature)
 	}
 
-	if err := algorithms.VerifySignature(signer, keyStore); err != nil {
+	if err := algorithms.VerifySignature(signer, keyStore, nil); err != nil {
 		return err
 	}
 
 	signer.Signature = badSignature
 
-	if err := algorithms.VerifySignature(signer, keyStore); err == nil {
-		return fmt.Errorf("unexpected verification success for bad signature")
+	if err := algorithms.VerifySignature(signer, keyStore, nil); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("unexpected result for bad signature: %v", err)
 	}
 
 	signer.Signature = expectedSignature
 	signer.SigningIdentity = badIdentity
 
-	if err := algorithms.VerifySignature(signer, keyStore); err == nil {
-		return fmt.Errorf("unexpected verification success for bad identity")
+	if err := algorithms.VerifySignature(signer, keyStore, nil); !errors.Is(err, algorithms.ErrUnknownSigner) {
+		return fmt.Errorf("unexpected result for bad identity: %v", err)
 	}
 
 	return nil
 }
+
+func TestMixedKeyTypes(t *testing.T) {
+	if err := testMixedKeyTypes(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testMixedKeyTypes() error {
+	seed := [32]byte{}
+
+	ed25519Key, err := examples.NewEd25519(seed[:])
+	if err != nil {
+		return err
+	}
+
+	p256Key, err := examples.NewECDSAP256(nil)
+	if err != nil {
+		return err
+	}
+
+	p384Key, err := examples.NewECDSAP384(nil)
+	if err != nil {
+		return err
+	}
+
+	keys := []interfaces.SigningKey{ed25519Key, p256Key, p384Key}
+	signatureLengths := []int{88, 88, 132}
+
+	keyStore := examples.NewVerificationKeyStore()
+	for _, key := range keys {
+		identity, err := key.Identity()
+		if err != nil {
+			return err
+		}
+
+		public, err := key.Public()
+		if err != nil {
+			return err
+		}
+
+		verificationKey, err := examples.ParseVerificationKey(public)
+		if err != nil {
+			return err
+		}
+
+		keyStore.Add(identity, verificationKey)
+	}
+
+	signers := []*primitives.Signer{}
+	for i, key := range keys {
+		signer := &primitives.Signer{}
+		if err := algorithms.Sign(signer, key, nil, func() error { return nil }); err != nil {
+			return err
+		}
+
+		if len(signer.Signature) != signatureLengths[i] {
+			return fmt.Errorf("unexpected signature length for key %d: %d", i, len(signer.Signature))
+		}
+
+		signers = append(signers, signer)
+	}
+
+	for i, signer := range signers {
+		if err := algorithms.VerifySignature(signer, keyStore, nil); err != nil {
+			return fmt.Errorf("key %d: %w", i, err)
+		}
+	}
+
+	// the verifier is chosen by the signature's code, per store. overriding it in one store leaves
+	// every other store alone.
+	rejecting := examples.NewVerificationKeyStore()
+	rejecting.SetVerifier(cesr.Ed25519Sig, rejectingVerifier{})
+	rejecting.Add(signers[0].SigningIdentity, examples.NewEd25519VerificationKey(signers[0].SigningIdentity))
+
+	if err := algorithms.VerifySignature(signers[0], rejecting, nil); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected the store's verifier to be used: %v", err)
+	}
+
+	if err := algorithms.VerifySignature(signers[0], keyStore, nil); err != nil {
+		return err
+	}
+
+	// (r, n-s) also satisfies the curve equation, but isn't accepted as a second signature
+	siger, err := cesr.ParseSiger(signers[1].Signature)
+	if err != nil {
+		return err
+	}
+
+	raw := siger.Raw()
+	sValue := new(big.Int).SetBytes(raw[32:])
+	new(big.Int).Sub(elliptic.P256().Params().N, sValue).FillBytes(raw[32:])
+
+	malleated, err := cesr.NewSiger(cesr.ECDSA256r1Sig, raw)
+	if err != nil {
+		return err
+	}
+
+	highS := &primitives.Signer{
+		SigningIdentity: signers[1].SigningIdentity,
+		Signature:       malleated.Qb64(),
+	}
+
+	if err := algorithms.VerifySignature(highS, keyStore, nil); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected a high-s signature to be rejected: %v", err)
+	}
+
+	// transferable keys verify the same way on either curve
+	for i, code := range map[int]string{1: cesr.ECDSA256r1, 2: cesr.ModuleECDSA384r1} {
+		verfer, err := cesr.ParseVerfer(signers[i].SigningIdentity)
+		if err != nil {
+			return err
+		}
+
+		transferable, err := cesr.NewMatter(code, verfer.Raw())
+		if err != nil {
+			return err
+		}
+
+		verificationKey, err := examples.ParseVerificationKey(transferable.Qb64())
+		if err != nil {
+			return err
+		}
+
+		keyStore.Add(transferable.Qb64(), verificationKey)
+
+		signer := &primitives.Signer{}
+		if err := algorithms.Sign(signer, identifiedKey{SigningKey: keys[i], identity: transferable.Qb64()}, nil, func() error { return nil }); err != nil {
+			return err
+		}
+
+		if err := algorithms.VerifySignature(signer, keyStore, nil); err != nil {
+			return fmt.Errorf("transferable key %s: %w", code, err)
+		}
+	}
+
+	// a p-256 identity claiming a p-384 signature
+	forged := &primitives.Signer{
+		SigningIdentity: signers[1].SigningIdentity,
+		Signature:       signers[2].Signature,
+	}
+
+	err = algorithms.VerifySignature(forged, keyStore, nil)
+	if !errors.Is(err, algorithms.ErrInvalidSignature) || !errors.Is(err, cesr.ErrUnexpectedCode) {
+		return fmt.Errorf("unexpected result for mismatched signature code: %v", err)
+	}
+
+	// right code, wrong key
+	forged.Signature = signers[1].Signature
+	forged.SigningIdentity = signers[2].SigningIdentity
+	if err := algorithms.VerifySignature(forged, keyStore, nil); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("unexpected result for mismatched key: %v", err)
+	}
+
+	return nil
+}
+
+type rejectingVerifier struct{}
+
+func (rejectingVerifier) Verify(signature, publicKey string, message []byte) error {
+	return fmt.Errorf("rejected")
+}
+
+// signs under an identity other than the key's own
+type identifiedKey struct {
+	interfaces.SigningKey
+	identity string
+}
+
+func (k identifiedKey) Identity() (string, error) {
+	return k.identity, nil
+}
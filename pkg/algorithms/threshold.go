@@ -0,0 +1,76 @@
+package algorithms
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// the signers whose approval a multi-signed record needs. each identity carries a weight, and the
+// threshold is met once the weights of the identities that signed sum to at least one.
+type Threshold struct {
+	identities []string
+	weights    []*big.Rat
+}
+
+// any required of the given identities, each weighing 1/required
+func NewThreshold(required uint, identities ...string) (Threshold, error) {
+	if required == 0 || required > uint(len(identities)) {
+		return Threshold{}, fmt.Errorf("cannot require %d of %d signers", required, len(identities))
+	}
+
+	weights := []string{}
+	for range identities {
+		weights = append(weights, fmt.Sprintf("1/%d", required))
+	}
+
+	return NewWeightedThreshold(identities, weights)
+}
+
+// weights are fractions such as "1/2" or "1", one for each identity
+func NewWeightedThreshold(identities []string, weights []string) (Threshold, error) {
+	if len(identities) != len(weights) {
+		return Threshold{}, fmt.Errorf("%d identities given %d weights", len(identities), len(weights))
+	}
+
+	threshold := Threshold{}
+	total := new(big.Rat)
+
+	for i, identity := range identities {
+		if slices.Contains(threshold.identities, identity) {
+			return Threshold{}, fmt.Errorf("duplicate signer %s", identity)
+		}
+
+		weight, ok := new(big.Rat).SetString(weights[i])
+		if !ok || weight.Sign() <= 0 || weight.Cmp(big.NewRat(1, 1)) > 0 {
+			return Threshold{}, fmt.Errorf("invalid weight %q for %s", weights[i], identity)
+		}
+
+		threshold.identities = append(threshold.identities, identity)
+		threshold.weights = append(threshold.weights, weight)
+		total.Add(total, weight)
+	}
+
+	if total.Cmp(big.NewRat(1, 1)) < 0 {
+		return Threshold{}, fmt.Errorf("weights sum to %s, so the threshold can never be met", total.RatString())
+	}
+
+	return threshold, nil
+}
+
+// in the order given, which is the order signatures are indexed against
+func (t Threshold) Identities() []string {
+	return slices.Clone(t.identities)
+}
+
+// identities the threshold doesn't name carry no weight, and each identity counts once
+func (t Threshold) Satisfied(signers []string) bool {
+	total := new(big.Rat)
+	for i, identity := range t.identities {
+		if slices.Contains(signers, identity) {
+			total.Add(total, t.weights[i])
+		}
+	}
+
+	return total.Cmp(big.NewRat(1, 1)) >= 0
+}
@@ -0,0 +1,233 @@
+package cesr_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+)
+
+func TestCesr(t *testing.T) {
+	if err := testCesr(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testCesr() error {
+	// a blake3 self-address produced before this package existed
+	id := `EIuB8-qRNMMGsLpJQFMgeJxWr_ppYahDfQh6mgvkdD2R`
+
+	diger, err := cesr.ParseDiger(id)
+	if err != nil {
+		return err
+	}
+
+	if diger.Code() != cesr.Blake3_256 || len(diger.Raw()) != 32 || diger.Qb64() != id {
+		return fmt.Errorf("unexpected digest: %s %d %s", diger.Code(), len(diger.Raw()), diger.Qb64())
+	}
+
+	if cesr.MustDigest(cesr.Blake3_256, diger.Raw()) != id {
+		return fmt.Errorf("unexpected digest encoding")
+	}
+
+	fromQb2, err := cesr.ParseQb2(diger.Qb2())
+	if err != nil {
+		return err
+	}
+
+	if fromQb2.Qb64() != id || len(diger.Qb2()) != 33 {
+		return fmt.Errorf("unexpected qb2 round trip: %s", fromQb2.Qb64())
+	}
+
+	sizes := []struct {
+		code   string
+		raw    int
+		length int
+	}{
+		{cesr.Ed25519N, 32, 44},
+		{cesr.Salt128, 16, 24},
+		{cesr.Ed25519Sig, 64, 88},
+		{cesr.ECDSA256r1, 33, 48},
+	}
+
+	for _, size := range sizes {
+		raw := bytes.Repeat([]byte{0xa5}, size.raw)
+
+		matter, err := cesr.NewMatter(size.code, raw)
+		if err != nil {
+			return err
+		}
+
+		qb64 := matter.Qb64()
+		if len(qb64) != size.length || !strings.HasPrefix(qb64, size.code) {
+			return fmt.Errorf("unexpected qb64 for %s: %s", size.code, qb64)
+		}
+
+		parsed, err := cesr.Parse(qb64)
+		if err != nil {
+			return err
+		}
+
+		if parsed.Code() != size.code || !bytes.Equal(parsed.Raw(), raw) {
+			return fmt.Errorf("unexpected round trip for %s", size.code)
+		}
+	}
+
+	failures := map[string]error{
+		id[:43]:                  cesr.ErrMalformed,
+		"E_" + id[2:]:            cesr.ErrMalformed, // non-zero pad bits
+		"Z" + id[1:]:             cesr.ErrUnknownCode,
+		"~" + id[1:]:             cesr.ErrUnknownCode,
+		"":                       cesr.ErrMalformed,
+		"EIuB8-qRNMMGsLpJQFMge!": cesr.ErrMalformed,
+	}
+
+	for qb64, expected := range failures {
+		if _, err := cesr.Parse(qb64); !errors.Is(err, expected) {
+			return fmt.Errorf("expected %v parsing %q: %v", expected, qb64, err)
+		}
+	}
+
+	if _, err := cesr.ParseVerfer(id); !errors.Is(err, cesr.ErrUnexpectedCode) {
+		return fmt.Errorf("expected a digest to be rejected as a key: %v", err)
+	}
+
+	if _, err := cesr.NewSiger(cesr.Blake3_256, make([]byte, 32)); !errors.Is(err, cesr.ErrUnexpectedCode) {
+		return fmt.Errorf("expected a digest code to be rejected for a signature: %v", err)
+	}
+
+	if _, err := cesr.NewDiger(cesr.Blake3_256, make([]byte, 31)); !errors.Is(err, cesr.ErrMalformed) {
+		return fmt.Errorf("expected a short digest to be rejected: %v", err)
+	}
+
+	return nil
+}
+
+func TestIndexer(t *testing.T) {
+	if err := testIndexer(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testIndexer() error {
+	sizes := []struct {
+		code    string
+		raw     int
+		indexed string
+		length  int
+	}{
+		{cesr.Ed25519Sig, 64, cesr.Ed25519IdxSig, 88},
+		{cesr.ECDSA256r1Sig, 64, cesr.ECDSA256r1IdxSig, 88},
+		{cesr.ModuleECDSA384r1Sig, 96, cesr.ModuleECDSA384r1IdxSig, 132},
+	}
+
+	for _, size := range sizes {
+		siger, err := cesr.NewSiger(size.code, bytes.Repeat([]byte{0xa5}, size.raw))
+		if err != nil {
+			return err
+		}
+
+		for _, index := range []uint{0, 1, cesr.MaxIndex} {
+			indexer, err := cesr.NewIndexer(siger, index)
+			if err != nil {
+				return err
+			}
+
+			qb64 := indexer.Qb64()
+			if len(qb64) != size.length || !strings.HasPrefix(qb64, size.indexed) {
+				return fmt.Errorf("unexpected qb64 for %s at %d: %s", size.code, index, qb64)
+			}
+
+			parsed, err := cesr.ParseIndexer(qb64)
+			if err != nil {
+				return err
+			}
+
+			unindexed, err := parsed.Siger()
+			if err != nil {
+				return err
+			}
+
+			if parsed.Index() != index || parsed.Code() != size.indexed || unindexed.Qb64() != siger.Qb64() {
+				return fmt.Errorf("unexpected round trip for %s at %d", size.code, index)
+			}
+		}
+	}
+
+	siger, err := cesr.NewSiger(cesr.Ed25519Sig, make([]byte, 64))
+	if err != nil {
+		return err
+	}
+
+	if _, err := cesr.NewIndexer(siger, cesr.MaxIndex+1); !errors.Is(err, cesr.ErrMalformed) {
+		return fmt.Errorf("expected an out of range index to be rejected: %v", err)
+	}
+
+	indexer, err := cesr.NewIndexer(siger, 2)
+	if err != nil {
+		return err
+	}
+
+	// the index and ondex differ
+	mismatched := cesr.ModuleECDSA384r1IdxSig + "AB" + siger.Qb64()[4:]
+
+	failures := map[string]error{
+		indexer.Qb64()[:87]:      cesr.ErrMalformed,
+		siger.Qb64():             cesr.ErrUnknownCode, // unindexed
+		"B" + indexer.Qb64()[1:]: cesr.ErrUnknownCode,
+		mismatched:               cesr.ErrMalformed,
+		"":                       cesr.ErrMalformed,
+	}
+
+	for qb64, expected := range failures {
+		if _, err := cesr.ParseIndexer(qb64); !errors.Is(err, expected) {
+			return fmt.Errorf("expected %v parsing %q: %v", expected, qb64, err)
+		}
+	}
+
+	return nil
+}
+
+func TestModuleCodes(t *testing.T) {
+	if err := testModuleCodes(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+// the codes this module assigns itself must not be read as any other primitive, in either table
+func testModuleCodes() error {
+	standard := []string{
+		cesr.Ed25519Seed, cesr.Ed25519N, cesr.X25519, cesr.Ed25519, cesr.Blake3_256, cesr.Blake2b_256,
+		cesr.Blake2s_256, cesr.SHA3_256, cesr.SHA2_256, cesr.ECDSA256k1Seed, cesr.ECDSA256r1Seed,
+		cesr.Salt128, cesr.Ed25519Sig, cesr.ECDSA256k1Sig, cesr.Blake3_512, cesr.Blake2b_512, cesr.SHA3_512,
+		cesr.SHA2_512, cesr.ECDSA256r1Sig, cesr.ECDSA256k1N, cesr.ECDSA256k1, cesr.ECDSA256r1N,
+		cesr.ECDSA256r1, cesr.Ed25519IdxSig, cesr.ECDSA256k1IdxSig, cesr.ECDSA256r1IdxSig,
+	}
+
+	module := []string{
+		cesr.ModuleECDSA384r1N, cesr.ModuleECDSA384r1, cesr.ModuleECDSA384r1Sig, cesr.ModuleECDSA384r1IdxSig,
+	}
+
+	seen := map[string]bool{}
+	for _, code := range append(standard, module...) {
+		if seen[code] {
+			if slices.Contains(module, code) {
+				return fmt.Errorf("module code %s collides with another code", code)
+			}
+
+			// standard codes may repeat across the two tables
+			continue
+		}
+
+		seen[code] = true
+	}
+
+	return nil
+}
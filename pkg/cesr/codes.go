@@ -0,0 +1,108 @@
+package cesr
+
+// derivation codes for the fixed size primitives this module produces or accepts
+const (
+	Ed25519Seed    = "A"
+	Ed25519N       = "B" // non-transferable ed25519 verification key
+	X25519         = "C"
+	Ed25519        = "D" // transferable ed25519 verification key
+	Blake3_256     = "E"
+	Blake2b_256    = "F"
+	Blake2s_256    = "G"
+	SHA3_256       = "H"
+	SHA2_256       = "I"
+	ECDSA256k1Seed = "J"
+	ECDSA256r1Seed = "Q"
+
+	Salt128       = "0A"
+	Ed25519Sig    = "0B"
+	ECDSA256k1Sig = "0C"
+	Blake3_512    = "0D"
+	Blake2b_512   = "0E"
+	SHA3_512      = "0F"
+	SHA2_512      = "0G"
+	ECDSA256r1Sig = "0I"
+
+	ECDSA256k1N = "1AAA"
+	ECDSA256k1  = "1AAB"
+	ECDSA256r1N = "1AAI"
+	ECDSA256r1  = "1AAJ"
+)
+
+// cesr assigns no codes to P-384 primitives, so this module assigns its own. each is distinct from
+// every code in either table above or below, so nothing here reads one as another primitive, but
+// other cesr implementations won't recognise them, and cesr may one day assign them elsewhere.
+const (
+	ModuleECDSA384r1N      = "0Z"   // compressed, non-transferable
+	ModuleECDSA384r1       = "0Y"   // compressed, transferable
+	ModuleECDSA384r1Sig    = "1AZZ" // r || s
+	ModuleECDSA384r1IdxSig = "0X"   // from the indexer table
+)
+
+// codes for indexed signatures, which carry the position of their signer in a list of signing
+// identities. they come from cesr's separate indexer table, so they overlap the codes above.
+const (
+	Ed25519IdxSig    = "A"
+	ECDSA256k1IdxSig = "C"
+	ECDSA256r1IdxSig = "E"
+)
+
+// raw sizes in bytes, by code
+var rawSizes = map[string]int{
+	Ed25519Seed:    32,
+	Ed25519N:       32,
+	X25519:         32,
+	Ed25519:        32,
+	Blake3_256:     32,
+	Blake2b_256:    32,
+	Blake2s_256:    32,
+	SHA3_256:       32,
+	SHA2_256:       32,
+	ECDSA256k1Seed: 32,
+	ECDSA256r1Seed: 32,
+
+	Salt128:       16,
+	Ed25519Sig:    64,
+	ECDSA256k1Sig: 64,
+	Blake3_512:    64,
+	Blake2b_512:   64,
+	SHA3_512:      64,
+	SHA2_512:      64,
+	ECDSA256r1Sig: 64,
+
+	ECDSA256k1N: 33,
+	ECDSA256k1:  33,
+	ECDSA256r1N: 33,
+	ECDSA256r1:  33,
+
+	ModuleECDSA384r1N:   49,
+	ModuleECDSA384r1:    49,
+	ModuleECDSA384r1Sig: 96,
+}
+
+var (
+	digestCodes       = []string{Blake3_256, Blake2b_256, Blake2s_256, SHA3_256, SHA2_256, Blake3_512, Blake2b_512, SHA3_512, SHA2_512}
+	verificationCodes = []string{Ed25519N, Ed25519, ECDSA256k1N, ECDSA256k1, ECDSA256r1N, ECDSA256r1, ModuleECDSA384r1N, ModuleECDSA384r1}
+	signatureCodes    = []string{Ed25519Sig, ECDSA256k1Sig, ECDSA256r1Sig, ModuleECDSA384r1Sig}
+	saltCodes         = []string{Salt128}
+)
+
+// the signature code produced by keys of each verification key code
+var signatureCodesByKey = map[string]string{
+	Ed25519N:          Ed25519Sig,
+	Ed25519:           Ed25519Sig,
+	ECDSA256k1N:       ECDSA256k1Sig,
+	ECDSA256k1:        ECDSA256k1Sig,
+	ECDSA256r1N:       ECDSA256r1Sig,
+	ECDSA256r1:        ECDSA256r1Sig,
+	ModuleECDSA384r1N: ModuleECDSA384r1Sig,
+	ModuleECDSA384r1:  ModuleECDSA384r1Sig,
+}
+
+// the indexed signature code for each signature code
+var indexedCodesBySignature = map[string]string{
+	Ed25519Sig:          Ed25519IdxSig,
+	ECDSA256k1Sig:       ECDSA256k1IdxSig,
+	ECDSA256r1Sig:       ECDSA256r1IdxSig,
+	ModuleECDSA384r1Sig: ModuleECDSA384r1IdxSig,
+}
@@ -0,0 +1,117 @@
+package cesr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// indexes are encoded in a single base64 character, limiting signers to 64
+const MaxIndex = 63
+
+const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// a signature qualified with the index of its signer. two character codes also carry an ondex (the
+// signer's index in a prior list of keys), which this module always sets to the index.
+type Indexer struct {
+	code  string
+	index uint
+	raw   []byte
+}
+
+func NewIndexer(siger *Siger, index uint) (*Indexer, error) {
+	code, known := indexedCodesBySignature[siger.Code()]
+	if !known {
+		return nil, fmt.Errorf("%w: %s signatures cannot be indexed", ErrUnknownCode, siger.Code())
+	}
+
+	if index > MaxIndex {
+		return nil, fmt.Errorf("%w: index %d exceeds %d", ErrMalformed, index, MaxIndex)
+	}
+
+	return &Indexer{code: code, index: index, raw: siger.Raw()}, nil
+}
+
+// parses fully qualified base64, validating the code, length and pad bits
+func ParseIndexer(qb64 string) (*Indexer, error) {
+	if qb64 == "" {
+		return nil, fmt.Errorf("%w: empty", ErrMalformed)
+	}
+
+	hard := 1
+	if qb64[0] == '0' {
+		hard = 2
+	}
+
+	if len(qb64) < 2*hard {
+		return nil, fmt.Errorf("%w: truncated code %s", ErrMalformed, qb64)
+	}
+
+	code := qb64[:hard]
+	signatureCode := signatureCodeFor(code)
+	if signatureCode == "" {
+		return nil, fmt.Errorf("%w: %s is not an indexed signature code", ErrUnknownCode, code)
+	}
+
+	soft := qb64[hard : 2*hard]
+	index := strings.IndexByte(alphabet, soft[0])
+	if index < 0 || strings.IndexByte(alphabet, soft[len(soft)-1]) != index {
+		return nil, fmt.Errorf("%w: invalid index %s", ErrMalformed, soft)
+	}
+
+	size := rawSizes[signatureCode]
+	pad := padSize(code + soft)
+	if len(qb64) != 2*hard+(pad+size)*4/3-pad {
+		return nil, fmt.Errorf("%w: %s indexed signatures are %d characters, got %d", ErrMalformed, code, 2*hard+(pad+size)*4/3-pad, len(qb64))
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(strings.Repeat("A", pad) + qb64[2*hard:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	for _, b := range decoded[:pad] {
+		if b != 0 {
+			return nil, fmt.Errorf("%w: non-zero pad bits in %s", ErrMalformed, qb64)
+		}
+	}
+
+	return &Indexer{code: code, index: uint(index), raw: decoded[pad:]}, nil
+}
+
+func (i Indexer) Code() string {
+	return i.code
+}
+
+func (i Indexer) Index() uint {
+	return i.index
+}
+
+func (i Indexer) Raw() []byte {
+	return slices.Clone(i.raw)
+}
+
+// the signature without its index
+func (i Indexer) Siger() (*Siger, error) {
+	return NewSiger(signatureCodeFor(i.code), i.raw)
+}
+
+func (i Indexer) Qb64() string {
+	soft := strings.Repeat(string(alphabet[i.index]), len(i.code))
+	pad := padSize(i.code + soft)
+	encoded := base64.URLEncoding.EncodeToString(append(make([]byte, pad), i.raw...))
+	return i.code + soft + encoded[pad:]
+}
+
+// helpers
+
+func signatureCodeFor(indexedCode string) string {
+	for signatureCode, code := range indexedCodesBySignature {
+		if code == indexedCode {
+			return signatureCode
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,144 @@
+package cesr
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMalformed      = errors.New("malformed cesr primitive")
+	ErrUnknownCode    = errors.New("unknown cesr code")
+	ErrUnexpectedCode = errors.New("unexpected cesr code")
+)
+
+// a fixed size primitive: a derivation code and the raw bytes it qualifies
+type Matter struct {
+	code string
+	raw  []byte
+}
+
+func NewMatter(code string, raw []byte) (*Matter, error) {
+	size, known := rawSizes[code]
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+
+	if len(raw) != size {
+		return nil, fmt.Errorf("%w: %s requires %d raw bytes, got %d", ErrMalformed, code, size, len(raw))
+	}
+
+	return &Matter{code: code, raw: slices.Clone(raw)}, nil
+}
+
+// parses fully qualified base64, validating the code, length and pad bits
+func Parse(qb64 string) (*Matter, error) {
+	code, err := ReadCode(qb64)
+	if err != nil {
+		return nil, err
+	}
+
+	size, known := rawSizes[code]
+	if !known {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+
+	pad := padSize(code)
+	if len(qb64) != len(code)+(pad+size)*4/3-pad {
+		return nil, fmt.Errorf("%w: %s primitives are %d characters, got %d", ErrMalformed, code, len(code)+(pad+size)*4/3-pad, len(qb64))
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(strings.Repeat("A", pad) + qb64[len(code):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	for _, b := range decoded[:pad] {
+		if b != 0 {
+			return nil, fmt.Errorf("%w: non-zero pad bits in %s", ErrMalformed, qb64)
+		}
+	}
+
+	return &Matter{code: code, raw: decoded[pad:]}, nil
+}
+
+// parses fully qualified binary
+func ParseQb2(qb2 []byte) (*Matter, error) {
+	return Parse(base64.URLEncoding.EncodeToString(qb2))
+}
+
+// reads the code from the front of qb64 without checking it is in the code table
+func ReadCode(qb64 string) (string, error) {
+	if qb64 == "" {
+		return "", fmt.Errorf("%w: empty", ErrMalformed)
+	}
+
+	var size int
+	switch selector := qb64[0]; {
+	case selector >= 'A' && selector <= 'Z', selector >= 'a' && selector <= 'z':
+		size = 1
+	case selector == '0':
+		size = 2
+	case selector >= '1' && selector <= '3':
+		size = 4
+	default:
+		return "", fmt.Errorf("%w: unsupported selector %q", ErrUnknownCode, selector)
+	}
+
+	if len(qb64) < size {
+		return "", fmt.Errorf("%w: truncated code %s", ErrMalformed, qb64)
+	}
+
+	return qb64[:size], nil
+}
+
+func (m Matter) Code() string {
+	return m.code
+}
+
+func (m Matter) Raw() []byte {
+	return slices.Clone(m.raw)
+}
+
+// the raw bytes are lead with zero bytes to align to base64, and the code then replaces the
+// characters those bytes encode to (or, for four character codes, is prepended)
+func (m Matter) Qb64() string {
+	pad := padSize(m.code)
+	encoded := base64.URLEncoding.EncodeToString(append(make([]byte, pad), m.raw...))
+	return m.code + encoded[pad:]
+}
+
+func (m Matter) Qb2() []byte {
+	// qb64 is always a multiple of four characters, so this cannot fail
+	qb2, _ := base64.URLEncoding.DecodeString(m.Qb64())
+	return qb2
+}
+
+// helpers
+
+func padSize(code string) int {
+	return len(code) % 4
+}
+
+func parseTyped(qb64 string, kind string, codes []string) (*Matter, error) {
+	matter, err := Parse(qb64)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(codes, matter.code) {
+		return nil, fmt.Errorf("%w: %s is not a %s code", ErrUnexpectedCode, matter.code, kind)
+	}
+
+	return matter, nil
+}
+
+func newTyped(code string, raw []byte, kind string, codes []string) (*Matter, error) {
+	if !slices.Contains(codes, code) {
+		return nil, fmt.Errorf("%w: %s is not a %s code", ErrUnexpectedCode, code, kind)
+	}
+
+	return NewMatter(code, raw)
+}
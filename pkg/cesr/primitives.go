@@ -0,0 +1,109 @@
+package cesr
+
+// a digest, such as a self-address
+type Diger struct {
+	Matter
+}
+
+func NewDiger(code string, raw []byte) (*Diger, error) {
+	matter, err := newTyped(code, raw, "digest", digestCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Diger{*matter}, nil
+}
+
+func ParseDiger(qb64 string) (*Diger, error) {
+	matter, err := parseTyped(qb64, "digest", digestCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Diger{*matter}, nil
+}
+
+// encodes the digest a hasher produced. the hasher's code fixes the digest's size, so an error here
+// is a programming error and panics.
+func MustDigest(code string, raw []byte) string {
+	diger, err := NewDiger(code, raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return diger.Qb64()
+}
+
+// a public verification key
+type Verfer struct {
+	Matter
+}
+
+func NewVerfer(code string, raw []byte) (*Verfer, error) {
+	matter, err := newTyped(code, raw, "verification key", verificationCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verfer{*matter}, nil
+}
+
+func ParseVerfer(qb64 string) (*Verfer, error) {
+	matter, err := parseTyped(qb64, "verification key", verificationCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verfer{*matter}, nil
+}
+
+// the code of signatures this key verifies
+func (v Verfer) SignatureCode() string {
+	return signatureCodesByKey[v.code]
+}
+
+// a signature
+type Siger struct {
+	Matter
+}
+
+func NewSiger(code string, raw []byte) (*Siger, error) {
+	matter, err := newTyped(code, raw, "signature", signatureCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Siger{*matter}, nil
+}
+
+func ParseSiger(qb64 string) (*Siger, error) {
+	matter, err := parseTyped(qb64, "signature", signatureCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Siger{*matter}, nil
+}
+
+// random salt, such as a nonce
+type Salter struct {
+	Matter
+}
+
+func NewSalter(code string, raw []byte) (*Salter, error) {
+	matter, err := newTyped(code, raw, "salt", saltCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Salter{*matter}, nil
+}
+
+func ParseSalter(qb64 string) (*Salter, error) {
+	matter, err := parseTyped(qb64, "salt", saltCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Salter{*matter}, nil
+}
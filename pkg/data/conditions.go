@@ -14,6 +14,13 @@ type Ordering interface {
 	String() string
 }
 
+// an ordering on a single column. keyset pagination requires one, so a cursor knows which value it holds
+type ColumnOrdering interface {
+	Ordering
+	Column() string
+	Direction() string // ASC or DESC
+}
+
 type AnyBuilder interface {
 	String(column string, values []any) string
 	Values(values []any) []any
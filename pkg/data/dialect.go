@@ -0,0 +1,26 @@
+package data
+
+import "reflect"
+
+// everything that differs between sql databases, so repositories can build queries for any store
+type Dialect interface {
+	// maps a (non-pointer) go type to a column type
+	ColumnType(t reflect.Type) (string, error)
+
+	// rewrites ? placeholders into the dialect's native form
+	ReplacePlaceholders(query string) string
+	QuoteIdentifier(name string) string
+	AnyBuilder() AnyBuilder
+	// either may be nil
+	LimitAndOffset(limit, offset *uint) string
+
+	Savepoint(name string) string
+	ReleaseSavepoint(name string) string
+	RollbackToSavepoint(name string) string
+
+	// an insert of named (:column) parameters
+	Insert(table string, columns []string) string
+	// selects the highest sequence number for each prefix matching preFilter, then applies
+	// condition (which may be empty) to the result. values are bound in that order.
+	LatestByPrefix(table, preFilter, condition string) string
+}
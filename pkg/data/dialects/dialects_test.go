@@ -0,0 +1,94 @@
+package dialects_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+)
+
+func TestPostgres(t *testing.T) {
+	if err := testPostgres(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testPostgres() error {
+	dialect := dialects.Postgres()
+
+	placeholders := dialect.ReplacePlaceholders(`SELECT * FROM "a?" WHERE b=? AND c='?' AND d IN (?, ?)`)
+	if !strings.EqualFold(placeholders, `SELECT * FROM "a?" WHERE b=$1 AND c='?' AND d IN ($2, $3)`) {
+		return fmt.Errorf("unexpected placeholders: %s", placeholders)
+	}
+
+	if quoted := dialect.QuoteIdentifier(`we"ird`); quoted != `"we""ird"` {
+		return fmt.Errorf("unexpected quoting: %s", quoted)
+	}
+
+	expression := expressions.Any("id", []any{"a", `b"\`, nil}, dialect.AnyBuilder())
+	if expression.String() != "id=ANY(?)" {
+		return fmt.Errorf("unexpected any: %s", expression.String())
+	}
+
+	values := expression.Values()
+	if len(values) != 1 {
+		return fmt.Errorf("unexpected any value count: %d", len(values))
+	}
+
+	array, err := values[0].(dialects.PostgresArray).Value()
+	if err != nil {
+		return err
+	}
+
+	if array != `{"a","b\"\\",NULL}` {
+		return fmt.Errorf("unexpected array literal: %s", array)
+	}
+
+	limit := uint(10)
+	offset := uint(20)
+	if clause := dialect.LimitAndOffset(&limit, &offset); clause != "LIMIT 10 OFFSET 20" {
+		return fmt.Errorf("unexpected limit and offset: %s", clause)
+	}
+
+	insert := dialect.Insert("record", []string{"id", "prefix"})
+	if insert != `INSERT INTO "record" ("id", "prefix") VALUES (:id, :prefix)` {
+		return fmt.Errorf("unexpected insert: %s", insert)
+	}
+
+	latest := dialect.LatestByPrefix("record", "account=?", "active=?")
+	if !strings.EqualFold(strings.Join(strings.Fields(latest), " "), `WITH latest AS (SELECT DISTINCT ON (prefix) * FROM "record" WHERE account=? ORDER BY prefix, sequence_number DESC) SELECT * FROM latest WHERE active=?`) {
+		return fmt.Errorf("unexpected latest query: %s", latest)
+	}
+
+	return nil
+}
+
+func TestSQLite(t *testing.T) {
+	if err := testSQLite(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testSQLite() error {
+	dialect := dialects.SQLite()
+
+	if query := dialect.ReplacePlaceholders("a=? AND b=?"); query != "a=? AND b=?" {
+		return fmt.Errorf("unexpected placeholders: %s", query)
+	}
+
+	expression := expressions.Any("id", []any{"a", "b"}, dialect.AnyBuilder())
+	if expression.String() != "id IN (?, ?)" || len(expression.Values()) != 2 {
+		return fmt.Errorf("unexpected any: %s", expression.String())
+	}
+
+	latest := dialect.LatestByPrefix("record", "account=?", "")
+	if !strings.EqualFold(strings.Join(strings.Fields(latest), " "), `WITH sequentialranks AS (SELECT ROW_NUMBER() OVER (PARTITION BY prefix ORDER BY sequence_number DESC) AS _rank, * FROM "record" WHERE account=?) SELECT * FROM sequentialranks WHERE _rank=1`) {
+		return fmt.Errorf("unexpected latest query: %s", latest)
+	}
+
+	return nil
+}
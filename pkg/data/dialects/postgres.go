@@ -0,0 +1,152 @@
+package dialects
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+type PostgresDialect struct {
+	standard
+}
+
+func Postgres() *PostgresDialect {
+	return &PostgresDialect{}
+}
+
+func (PostgresDialect) ColumnType(t reflect.Type) (string, error) {
+	switch t {
+	case reflect.TypeOf(primitives.Timestamp{}), reflect.TypeOf(time.Time{}):
+		return "TIMESTAMPTZ", nil
+	case reflect.TypeOf(primitives.StringList{}):
+		return "TEXT", nil
+	case reflect.TypeOf([]byte{}):
+		return "BYTEA", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "TEXT", nil
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT", nil
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "BIGINT", nil
+	case reflect.Uint, reflect.Uint64:
+		// sequence numbers are uint64 but will never approach the signed limit
+		return "BIGINT", nil
+	case reflect.Float32:
+		return "REAL", nil
+	case reflect.Float64:
+		return "DOUBLE PRECISION", nil
+	default:
+		return "", fmt.Errorf("unsupported column type: %s", t)
+	}
+}
+
+// ? becomes $1, $2, ... skipping anything inside string literals or quoted identifiers
+func (PostgresDialect) ReplacePlaceholders(query string) string {
+	var builder strings.Builder
+	var quote rune
+	n := 0
+
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+
+		builder.WriteRune(c)
+	}
+
+	return builder.String()
+}
+
+func (PostgresDialect) AnyBuilder() data.AnyBuilder {
+	return &PostgresAnyBuilder{}
+}
+
+// DISTINCT ON avoids materialising a rank for every row, and keeps the result to table columns
+func (d PostgresDialect) LatestByPrefix(table, preFilter, condition string) string {
+	query := fmt.Sprintf(
+		`WITH latest AS (SELECT DISTINCT ON (prefix) *
+			FROM %s
+			WHERE %s
+			ORDER BY prefix, sequence_number DESC) SELECT *
+			FROM latest`,
+		d.QuoteIdentifier(table),
+		preFilter,
+	)
+
+	if condition != "" {
+		query += fmt.Sprintf(" WHERE %s", condition)
+	}
+
+	return query
+}
+
+type PostgresAnyBuilder struct{}
+
+func (PostgresAnyBuilder) String(column string, values []any) string {
+	return fmt.Sprintf("%s=ANY(?)", column)
+}
+
+func (PostgresAnyBuilder) Values(values []any) []any {
+	return []any{PostgresArray(values)}
+}
+
+// encodes as an array literal, so no driver-specific array support is needed. the element type is
+// inferred by postgres from the column being compared.
+type PostgresArray []any
+
+func (a PostgresArray) Value() (driver.Value, error) {
+	elements := []string{}
+
+	for _, value := range a {
+		if valuer, ok := value.(driver.Valuer); ok {
+			var err error
+			value, err = valuer.Value()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		switch v := value.(type) {
+		case nil:
+			elements = append(elements, "NULL")
+		case string:
+			elements = append(elements, quoteArrayElement(v))
+		case []byte:
+			elements = append(elements, quoteArrayElement(string(v)))
+		case time.Time:
+			elements = append(elements, quoteArrayElement(v.Format(time.RFC3339Nano)))
+		default:
+			elements = append(elements, quoteArrayElement(fmt.Sprintf("%v", v)))
+		}
+	}
+
+	return "{" + strings.Join(elements, ",") + "}", nil
+}
+
+func quoteArrayElement(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
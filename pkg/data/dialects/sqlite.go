@@ -0,0 +1,82 @@
+package dialects
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+type SQLiteDialect struct {
+	standard
+}
+
+func SQLite() *SQLiteDialect {
+	return &SQLiteDialect{}
+}
+
+func (SQLiteDialect) ColumnType(t reflect.Type) (string, error) {
+	switch t {
+	case reflect.TypeOf(primitives.Timestamp{}), reflect.TypeOf(time.Time{}):
+		return "DATETIME", nil
+	case reflect.TypeOf(primitives.StringList{}):
+		return "TEXT", nil
+	case reflect.TypeOf([]byte{}):
+		return "BLOB", nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "TEXT", nil
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "BIGINT", nil
+	case reflect.Float32, reflect.Float64:
+		return "REAL", nil
+	default:
+		return "", fmt.Errorf("unsupported column type: %s", t)
+	}
+}
+
+func (SQLiteDialect) ReplacePlaceholders(query string) string {
+	return query
+}
+
+func (SQLiteDialect) AnyBuilder() data.AnyBuilder {
+	return &SQLiteAnyBuilder{}
+}
+
+func (d SQLiteDialect) LatestByPrefix(table, preFilter, condition string) string {
+	query := fmt.Sprintf(
+		`WITH sequentialranks AS (SELECT ROW_NUMBER() OVER (PARTITION BY prefix ORDER BY sequence_number DESC) AS _rank, *
+			FROM %s
+			WHERE %s) SELECT *
+			FROM sequentialranks
+			WHERE _rank=1`,
+		d.QuoteIdentifier(table),
+		preFilter,
+	)
+
+	if condition != "" {
+		query += fmt.Sprintf(" AND %s", condition)
+	}
+
+	return query
+}
+
+type SQLiteAnyBuilder struct{}
+
+func (SQLiteAnyBuilder) String(column string, values []any) string {
+	placeholders := slices.Repeat([]string{"?"}, len(values))
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+}
+
+func (SQLiteAnyBuilder) Values(values []any) []any {
+	return values
+}
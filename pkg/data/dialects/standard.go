@@ -0,0 +1,53 @@
+package dialects
+
+import (
+	"fmt"
+	"strings"
+)
+
+// the parts of sqlite and postgres that agree with each other
+type standard struct{}
+
+func (standard) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (standard) LimitAndOffset(limit, offset *uint) string {
+	clauses := []string{}
+
+	if limit != nil {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", *limit))
+	}
+
+	if offset != nil {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", *offset))
+	}
+
+	return strings.Join(clauses, " ")
+}
+
+func (s standard) Insert(table string, columns []string) string {
+	quoted := []string{}
+	for _, column := range columns {
+		quoted = append(quoted, s.QuoteIdentifier(column))
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (:%s)",
+		s.QuoteIdentifier(table),
+		strings.Join(quoted, ", "),
+		strings.Join(columns, ", :"),
+	)
+}
+
+func (s standard) Savepoint(name string) string {
+	return fmt.Sprintf("SAVEPOINT %s", s.QuoteIdentifier(name))
+}
+
+func (s standard) ReleaseSavepoint(name string) string {
+	return fmt.Sprintf("RELEASE SAVEPOINT %s", s.QuoteIdentifier(name))
+}
+
+func (s standard) RollbackToSavepoint(name string) string {
+	return fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", s.QuoteIdentifier(name))
+}
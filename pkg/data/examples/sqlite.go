@@ -3,18 +3,43 @@ package examples
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"slices"
-	"strings"
+	"maps"
+	"net/url"
+	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 type SQLiteStore struct {
-	db *sqlx.DB
-	tx *sqlx.Tx
+	db     *sqlx.DB
+	writer *sqlx.DB // the same as db unless file-backed
+}
+
+type SQLiteOptions struct {
+	JournalMode string        // WAL lets readers proceed while a write is in progress
+	BusyTimeout time.Duration // how long a connection waits on a lock before failing
+	Synchronous string        // OFF, NORMAL, FULL or EXTRA
+	ForeignKeys bool
+
+	// applies to readers. sqlite permits a single writer, so all writes share one connection.
+	MaxOpenConnections int
+}
+
+func DefaultSQLiteOptions() *SQLiteOptions {
+	return &SQLiteOptions{
+		JournalMode:        "WAL",
+		BusyTimeout:        5 * time.Second,
+		Synchronous:        "NORMAL",
+		ForeignKeys:        true,
+		MaxOpenConnections: runtime.NumCPU(),
+	}
 }
 
 func NewInMemorySQLiteStore() (*SQLiteStore, error) {
@@ -23,84 +48,210 @@ func NewInMemorySQLiteStore() (*SQLiteStore, error) {
 		return nil, err
 	}
 
+	// every connection to :memory: would open a separate, empty database
+	db.SetMaxOpenConns(1)
+
+	unsafe := db.Unsafe() // the unsafe here allows us to gracefully ignore computed columns
+
 	return &SQLiteStore{
-		db: db.Unsafe(), // the unsafe here allows us to gracefully ignore computed columns
-		tx: nil,
+		db:     unsafe,
+		writer: unsafe,
 	}, nil
 }
 
-func (s SQLiteStore) Sql() data.SQLStore {
-	if s.tx == nil {
-		return s.db
-	} else {
-		return s.tx
+// pass nil options to use DefaultSQLiteOptions()
+func NewSQLiteStore(path string, options *SQLiteOptions) (*SQLiteStore, error) {
+	if options == nil {
+		options = DefaultSQLiteOptions()
 	}
+
+	params := url.Values{}
+	params.Set("_busy_timeout", strconv.FormatInt(options.BusyTimeout.Milliseconds(), 10))
+	params.Set("_synchronous", options.Synchronous)
+	params.Set("_foreign_keys", strconv.FormatBool(options.ForeignKeys))
+
+	writerParams := url.Values{}
+	maps.Copy(writerParams, params)
+	writerParams.Set("_journal_mode", options.JournalMode)
+	// take the write lock when the transaction begins, rather than failing to upgrade a read lock
+	writerParams.Set("_txlock", "immediate")
+
+	writer, err := sqlx.Open("sqlite3", sqliteDSN(path, writerParams))
+	if err != nil {
+		return nil, err
+	}
+
+	writer.SetMaxOpenConns(1)
+
+	// creates the file and sets the journal mode before any reader connects
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	params.Set("mode", "ro")
+
+	db, err := sqlx.Open("sqlite3", sqliteDSN(path, params))
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	if options.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(options.MaxOpenConnections)
+		db.SetMaxIdleConns(options.MaxOpenConnections)
+	}
+
+	return &SQLiteStore{
+		db:     db.Unsafe(),
+		writer: writer.Unsafe(),
+	}, nil
 }
 
-func (s *SQLiteStore) BeginTransaction(ctx context.Context, opts *sql.TxOptions) error {
-	if s.tx != nil {
-		return fmt.Errorf("transaction in progress")
+// sqlite decodes the path of a uri filename, so characters like ? and # have to be escaped
+func sqliteDSN(path string, params url.Values) string {
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: path}).EscapedPath(),
+		RawQuery: params.Encode(),
 	}
 
-	var err error
-	s.tx, err = s.db.BeginTxx(ctx, opts)
+	return dsn.String()
+}
+
+func (s *SQLiteStore) Sql(ctx context.Context) data.SQLStore {
+	if tx, ok := data.TransactionFromContext(ctx, s); ok {
+		return tx
+	}
+
+	if s.writer == s.db {
+		return sqlxStore{DB: s.db}
+	}
+
+	return &splitSQLStore{
+		reader: s.db,
+		writer: s.writer,
+	}
+}
+
+func (s *SQLiteStore) Close() error {
+	if s.writer != s.db {
+		if err := s.writer.Close(); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) BeginTransaction(ctx context.Context, opts *sql.TxOptions) (context.Context, error) {
+	if _, ok := data.TransactionFromContext(ctx, s); ok {
+		return nil, fmt.Errorf("transaction in progress")
+	}
+
+	// transactions may write, so they always run on the writer
+	tx, err := s.writer.BeginTxx(ctx, opts)
 	if err != nil {
-		s.tx = nil
-		return err
+		return nil, err
 	}
 
-	return nil
+	return data.ContextWithTransaction(ctx, s, sqlxTransaction{Tx: tx}), nil
 }
 
-func (s *SQLiteStore) CommitTransaction() error {
-	if s.tx == nil {
+func (s *SQLiteStore) CommitTransaction(ctx context.Context) error {
+	tx, ok := data.TransactionFromContext(ctx, s)
+	if !ok {
 		return fmt.Errorf("no transaction in progress")
 	}
 
-	if err := s.tx.Commit(); err != nil {
-		return err
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) RollbackTransaction(ctx context.Context) error {
+	tx, ok := data.TransactionFromContext(ctx, s)
+	if !ok {
+		return fmt.Errorf("no transaction in progress")
 	}
 
-	s.tx = nil
+	return tx.Rollback()
+}
 
-	return nil
+func (*SQLiteStore) Dialect() data.Dialect {
+	return dialects.SQLite()
 }
 
-func (s *SQLiteStore) RollbackTransaction() error {
-	if s.tx == nil {
-		return fmt.Errorf("no transaction in progress")
+func (*SQLiteStore) IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
 	}
 
-	if err := s.tx.Rollback(); err != nil {
-		return err
+	return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func (*SQLiteStore) IsPrimaryKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
 	}
 
-	s.tx = nil
+	return sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
 
-	return nil
+// routes reads to the reader pool and writes to the single writer connection
+type splitSQLStore struct {
+	reader *sqlx.DB
+	writer *sqlx.DB
 }
 
-func (*SQLiteStore) ReplacePlaceholders(query string) string {
-	return query
+func (s splitSQLStore) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.writer.ExecContext(ctx, query, args...)
 }
 
-type AnyBuilder struct{}
+func (s splitSQLStore) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return s.reader.GetContext(ctx, dest, query, args...)
+}
 
-func NewAnyBuilder() *AnyBuilder {
-	return &AnyBuilder{}
+func (s splitSQLStore) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	return s.writer.NamedExecContext(ctx, query, arg)
 }
 
-func (AnyBuilder) String(column string, values []any) string {
-	placeholders := slices.Repeat([]string{"?"}, len(values))
-	// for postgres i believe it would just be:
-	//  expression := fmt.Sprintf("%s=ANY(?)", column)
-	expression := fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+func (s splitSQLStore) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return s.reader.SelectContext(ctx, dest, query, args...)
+}
+
+func (s splitSQLStore) QueryRowsContext(ctx context.Context, query string, args ...any) (data.Rows, error) {
+	return queryRows(s.reader.QueryxContext(ctx, query, args...))
+}
+
+// sqlx returns *sqlx.Rows, which satisfies data.Rows but not the method signature
+type sqlxStore struct {
+	*sqlx.DB
+}
+
+func (s sqlxStore) QueryRowsContext(ctx context.Context, query string, args ...any) (data.Rows, error) {
+	return queryRows(s.QueryxContext(ctx, query, args...))
+}
+
+type sqlxTransaction struct {
+	*sqlx.Tx
+}
 
-	return expression
+func (t sqlxTransaction) QueryRowsContext(ctx context.Context, query string, args ...any) (data.Rows, error) {
+	return queryRows(t.QueryxContext(ctx, query, args...))
 }
 
-func (AnyBuilder) Values(values []any) []any {
-	// for postgres it would be something like:
-	//  return []any{pq.Array(values)}
-	return values
+// avoids returning a nil *sqlx.Rows as a non-nil data.Rows
+func queryRows(rows *sqlx.Rows, err error) (data.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+type AnyBuilder = dialects.SQLiteAnyBuilder
+
+func NewAnyBuilder() *AnyBuilder {
+	return &AnyBuilder{}
 }
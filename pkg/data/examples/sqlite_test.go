@@ -0,0 +1,258 @@
+package examples_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	interfaces "github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+type Counter struct {
+	primitives.VerifiableRecorder
+	Name  string `db:"name" json:"name"`
+	Count int    `db:"count" json:"count"`
+}
+
+func (*Counter) TableName() string {
+	return `counter`
+}
+
+func TestFileBackedSQLiteStore(t *testing.T) {
+	if err := testFileBackedSQLiteStore(t.TempDir()); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testFileBackedSQLiteStore(directory string) error {
+	ctx := context.Background()
+
+	store, err := examples.NewSQLiteStore(filepath.Join(directory, "test.db"), nil)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var mode string
+	if err := store.Sql(ctx).GetContext(ctx, &mode, "PRAGMA journal_mode"); err != nil {
+		return err
+	}
+
+	if mode != "wal" {
+		return fmt.Errorf("unexpected journal mode: %s", mode)
+	}
+
+	r := repository.NewVerifiableRepository[*Counter](store, true, true, interfaces.NewNoncer())
+
+	if err := schema.Apply[*Counter](ctx, store, dialects.SQLite(), r.SchemaOptions()); err != nil {
+		return err
+	}
+
+	shared := &Counter{Name: "shared"}
+	if err := r.CreateVersion(ctx, shared); err != nil {
+		return err
+	}
+
+	const writers = 8
+	const increments = 5
+
+	errs := make(chan error, writers)
+	wg := sync.WaitGroup{}
+
+	for i := range writers {
+		wg.Go(func() {
+			// a chain of its own, and contention on the shared chain
+			own := &Counter{Name: fmt.Sprintf("writer-%d", i)}
+			for range increments {
+				own.Count++
+				if err := r.CreateVersion(ctx, own); err != nil {
+					errs <- err
+					return
+				}
+
+				if _, err := r.UpdateWithRetry(ctx, shared.GetPrefix(), func(c *Counter) error {
+					c.Count++
+					return nil
+				}); err != nil && !isExhausted(err) {
+					errs <- err
+					return
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		return err
+	}
+
+	report, err := r.VerifyChain(ctx, shared.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if !report.Intact() {
+		return fmt.Errorf("shared chain damaged: %+v", report)
+	}
+
+	latest := &Counter{}
+	if err := r.GetLatestByPrefix(ctx, latest, shared.GetPrefix()); err != nil {
+		return err
+	}
+
+	if int(latest.GetSequenceNumber()) != latest.Count {
+		return fmt.Errorf("lost update: sequence number %d, count %d", latest.GetSequenceNumber(), latest.Count)
+	}
+
+	return nil
+}
+
+// heavy contention can exhaust the retries, which is a legitimate outcome
+func isExhausted(err error) bool {
+	return errors.Is(err, repository.ErrSequenceConflict)
+}
+
+func TestSQLiteStorePath(t *testing.T) {
+	if err := testSQLiteStorePath(t.TempDir()); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testSQLiteStorePath(directory string) error {
+	ctx := context.Background()
+
+	// characters that would otherwise end the path or start an escape
+	path := filepath.Join(directory, "odd?name#with%20escapes.db")
+
+	store, err := examples.NewSQLiteStore(path, nil)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	r := repository.NewVerifiableRepository[*Counter](store, true, true, interfaces.NewNoncer())
+
+	if err := schema.Apply[*Counter](ctx, store, dialects.SQLite(), r.SchemaOptions()); err != nil {
+		return err
+	}
+
+	record := &Counter{Name: "escaped"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	// the reader opens the same file as the writer
+	if err := r.GetById(ctx, &Counter{}, record.GetId()); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func TestContextTransactions(t *testing.T) {
+	if err := testContextTransactions(t.TempDir()); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testContextTransactions(directory string) error {
+	ctx := context.Background()
+
+	store, err := examples.NewSQLiteStore(filepath.Join(directory, "test.db"), nil)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	r := repository.NewVerifiableRepository[*Counter](store, true, true, interfaces.NewNoncer())
+
+	if err := schema.Apply[*Counter](ctx, store, dialects.SQLite(), r.SchemaOptions()); err != nil {
+		return err
+	}
+
+	txCtx, err := store.BeginTransaction(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.BeginTransaction(txCtx, nil); err == nil {
+		return fmt.Errorf("expected an error beginning a transaction within a transaction")
+	}
+
+	record := &Counter{Name: "transactional"}
+	if err := r.CreateVersion(txCtx, record); err != nil {
+		return err
+	}
+
+	// visible inside the transaction, but not to other callers
+	if err := r.GetById(txCtx, &Counter{}, record.GetId()); err != nil {
+		return err
+	}
+
+	if err := r.GetById(ctx, &Counter{}, record.GetId()); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("uncommitted record visible outside transaction: %v", err)
+	}
+
+	// a concurrent transaction waits for the writer rather than joining ours
+	committed := make(chan error, 1)
+	go func() {
+		otherCtx, err := store.BeginTransaction(ctx, nil)
+		if err != nil {
+			committed <- err
+			return
+		}
+
+		if err := r.CreateVersion(otherCtx, &Counter{Name: "concurrent"}); err != nil {
+			committed <- errors.Join(err, store.RollbackTransaction(otherCtx))
+			return
+		}
+
+		committed <- store.CommitTransaction(otherCtx)
+	}()
+
+	if err := store.RollbackTransaction(txCtx); err != nil {
+		return err
+	}
+
+	if err := <-committed; err != nil {
+		return err
+	}
+
+	if err := r.GetById(ctx, &Counter{}, record.GetId()); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("rolled back record visible: %v", err)
+	}
+
+	if err := store.CommitTransaction(ctx); err == nil {
+		return fmt.Errorf("expected an error committing without a transaction")
+	}
+
+	records := []*Counter{}
+	if err := r.Select(ctx, &records, expressions.Equal("name", "concurrent"), nil, nil); err != nil {
+		return err
+	}
+
+	if len(records) != 1 {
+		return fmt.Errorf("unexpected record count: %d", len(records))
+	}
+
+	return nil
+}
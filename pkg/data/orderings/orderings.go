@@ -1,6 +1,11 @@
 package orderings
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+)
 
 type AscendingOrdering struct {
 	column string
@@ -14,6 +19,14 @@ func (o AscendingOrdering) String() string {
 	return fmt.Sprintf("ORDER BY %s ASC", o.column)
 }
 
+func (o AscendingOrdering) Column() string {
+	return o.column
+}
+
+func (AscendingOrdering) Direction() string {
+	return "ASC"
+}
+
 type DescendingOrdering struct {
 	column string
 }
@@ -25,3 +38,29 @@ func Descending(column string) *DescendingOrdering {
 func (o DescendingOrdering) String() string {
 	return fmt.Sprintf("ORDER BY %s DESC", o.column)
 }
+
+func (o DescendingOrdering) Column() string {
+	return o.column
+}
+
+func (DescendingOrdering) Direction() string {
+	return "DESC"
+}
+
+// orders by the first ordering, breaking ties with the rest
+type CompositeOrdering struct {
+	orderings []data.ColumnOrdering
+}
+
+func Composite(orderings ...data.ColumnOrdering) *CompositeOrdering {
+	return &CompositeOrdering{orderings: orderings}
+}
+
+func (o CompositeOrdering) String() string {
+	terms := []string{}
+	for _, ordering := range o.orderings {
+		terms = append(terms, fmt.Sprintf("%s %s", ordering.Column(), ordering.Direction()))
+	}
+
+	return fmt.Sprintf("ORDER BY %s", strings.Join(terms, ", "))
+}
@@ -10,14 +10,49 @@ type SQLStore interface {
 	GetContext(ctx context.Context, dest any, query string, args ...any) error
 	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
 	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	// the rows hold a connection until they are closed
+	QueryRowsContext(ctx context.Context, query string, args ...any) (Rows, error)
+}
+
+type Rows interface {
+	Next() bool
+	StructScan(dest any) error
+	Err() error
+	Close() error
+}
+
+type Transaction interface {
+	SQLStore
+	Commit() error
+	Rollback() error
 }
 
 type Store interface {
-	Sql() SQLStore // May be an sql.Tx or sql.DB
+	Sql(ctx context.Context) SQLStore // the transaction carried by ctx, if any, otherwise the database
+
+	// the returned context carries the transaction. pass it to anything that should take part.
+	BeginTransaction(ctx context.Context, opts *sql.TxOptions) (context.Context, error)
+	CommitTransaction(ctx context.Context) error
+	RollbackTransaction(ctx context.Context) error
+
+	Dialect() Dialect
 
-	BeginTransaction(ctx context.Context, opts *sql.TxOptions) error
-	CommitTransaction() error
-	RollbackTransaction() error
+	// reports whether err is a unique constraint violation, other than of the primary key
+	IsUniqueViolation(err error) bool
+	// reports whether err is a primary key violation
+	IsPrimaryKeyViolation(err error) bool
+}
+
+// keyed by store, so transactions on different stores can share a context
+type transactionKey struct {
+	store Store
+}
+
+func ContextWithTransaction(ctx context.Context, store Store, tx Transaction) context.Context {
+	return context.WithValue(ctx, transactionKey{store: store}, tx)
+}
 
-	ReplacePlaceholders(query string) string
+func TransactionFromContext(ctx context.Context, store Store) (Transaction, bool) {
+	tx, ok := ctx.Value(transactionKey{store: store}).(Transaction)
+	return tx, ok
 }
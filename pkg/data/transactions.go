@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type savepointKey struct {
+	store Store
+}
+
+// runs fn in a transaction, committing if it returns nil and rolling back if it returns an error or
+// panics. if ctx already carries a transaction for store, fn runs in a savepoint within it instead,
+// so units of work compose.
+func WithTransaction(ctx context.Context, store Store, fn func(ctx context.Context) error) (err error) {
+	if _, ok := TransactionFromContext(ctx, store); ok {
+		return withSavepoint(ctx, store, fn)
+	}
+
+	txCtx, err := store.BeginTransaction(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = store.RollbackTransaction(txCtx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(txCtx); err != nil {
+		if rollbackErr := store.RollbackTransaction(txCtx); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return store.CommitTransaction(txCtx)
+}
+
+func withSavepoint(ctx context.Context, store Store, fn func(ctx context.Context) error) error {
+	depth, _ := ctx.Value(savepointKey{store: store}).(int)
+	depth++
+
+	dialect := store.Dialect()
+	name := fmt.Sprintf("savepoint_%d", depth)
+	tx := store.Sql(ctx)
+
+	if _, err := tx.ExecContext(ctx, dialect.Savepoint(name)); err != nil {
+		return err
+	}
+
+	rollback := func() error {
+		if _, err := tx.ExecContext(ctx, dialect.RollbackToSavepoint(name)); err != nil {
+			return err
+		}
+
+		// rolling back to a savepoint leaves it open
+		_, err := tx.ExecContext(ctx, dialect.ReleaseSavepoint(name))
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, savepointKey{store: store}, depth)); err != nil {
+		if rollbackErr := rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, dialect.ReleaseSavepoint(name))
+	return err
+}
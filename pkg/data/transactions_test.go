@@ -0,0 +1,264 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	interfaces "github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+type Account struct {
+	primitives.SignableRecorder
+	Name string `db:"name" json:"name"`
+}
+
+func (*Account) TableName() string {
+	return `account`
+}
+
+type Audit struct {
+	primitives.VerifiableRecorder
+	Event string `db:"event" json:"event"`
+}
+
+func (*Audit) TableName() string {
+	return `audit`
+}
+
+func TestWithTransaction(t *testing.T) {
+	if err := testWithTransaction(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testWithTransaction() error {
+	ctx := context.Background()
+
+	store, err := examples.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	key, err := interfaces.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	identity, err := key.Identity()
+	if err != nil {
+		return err
+	}
+
+	keyStore := interfaces.NewVerificationKeyStore()
+	keyStore.Add(identity, key)
+
+	noncer := interfaces.NewNoncer()
+	accounts := repository.NewSignableRepository[*Account](store, true, true, noncer, key, keyStore)
+	audits := repository.NewVerifiableRepository[*Audit](store, true, true, noncer)
+
+	if err := schema.Apply[*Account](ctx, store, dialects.SQLite(), accounts.SchemaOptions()); err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*Audit](ctx, store, dialects.SQLite(), audits.SchemaOptions()); err != nil {
+		return err
+	}
+
+	create := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if err := accounts.CreateVersion(ctx, &Account{Name: name}); err != nil {
+				return err
+			}
+
+			return audits.CreateVersion(ctx, &Audit{Event: "created " + name})
+		}
+	}
+
+	count := func() (int, int, error) {
+		accountRecords := []*Account{}
+		if err := accounts.Select(ctx, &accountRecords, expressions.NotNull("id"), nil, nil); err != nil {
+			return 0, 0, err
+		}
+
+		auditRecords := []*Audit{}
+		if err := audits.Select(ctx, &auditRecords, expressions.NotNull("id"), nil, nil); err != nil {
+			return 0, 0, err
+		}
+
+		return len(accountRecords), len(auditRecords), nil
+	}
+
+	if err := data.WithTransaction(ctx, store, create("alice")); err != nil {
+		return err
+	}
+
+	failure := errors.New("failure")
+	if err := data.WithTransaction(ctx, store, func(ctx context.Context) error {
+		if err := create("bob")(ctx); err != nil {
+			return err
+		}
+
+		return failure
+	}); !errors.Is(err, failure) {
+		return fmt.Errorf("unexpected error: %v", err)
+	}
+
+	if err := withPanic(ctx, store, create("carol")); err == nil {
+		return fmt.Errorf("expected a panic")
+	}
+
+	// the inner unit of work fails, but the outer one carries on and commits
+	if err := data.WithTransaction(ctx, store, func(ctx context.Context) error {
+		if err := create("dave")(ctx); err != nil {
+			return err
+		}
+
+		if err := data.WithTransaction(ctx, store, func(ctx context.Context) error {
+			if err := create("eve")(ctx); err != nil {
+				return err
+			}
+
+			return failure
+		}); !errors.Is(err, failure) {
+			return fmt.Errorf("unexpected nested error: %v", err)
+		}
+
+		return data.WithTransaction(ctx, store, create("frank"))
+	}); err != nil {
+		return err
+	}
+
+	accountCount, auditCount, err := count()
+	if err != nil {
+		return err
+	}
+
+	// alice, dave and frank
+	if accountCount != 3 || auditCount != 3 {
+		return fmt.Errorf("unexpected counts: %d accounts, %d audits", accountCount, auditCount)
+	}
+
+	return nil
+}
+
+func TestConflictInTransaction(t *testing.T) {
+	if err := testConflictInTransaction(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testConflictInTransaction() error {
+	ctx := context.Background()
+
+	store, err := examples.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	key, err := interfaces.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	identity, err := key.Identity()
+	if err != nil {
+		return err
+	}
+
+	keyStore := interfaces.NewVerificationKeyStore()
+	keyStore.Add(identity, key)
+
+	accounts := repository.NewSignableRepository[*Account](store, true, true, interfaces.NewNoncer(), key, keyStore)
+	if err := schema.Apply[*Account](ctx, store, dialects.SQLite(), accounts.SchemaOptions()); err != nil {
+		return err
+	}
+
+	account := &Account{Name: "alice"}
+	if err := accounts.CreateVersion(ctx, account); err != nil {
+		return err
+	}
+
+	// conflicts roll back to a savepoint, leaving the transaction usable for reading the latest version
+	return data.WithTransaction(ctx, store, func(ctx context.Context) error {
+		stale := &Account{}
+		if err := accounts.GetLatestByPrefix(ctx, stale, account.GetPrefix()); err != nil {
+			return err
+		}
+
+		if _, err := accounts.UpdateWithRetry(ctx, account.GetPrefix(), func(latest *Account) error {
+			latest.Name = "bob"
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		stale.Name = "stale"
+		err := accounts.CreateVersion(ctx, stale)
+
+		var conflict repository.VersionConflictError[*Account]
+		if !errors.As(err, &conflict) {
+			return fmt.Errorf("expected a version conflict: %v", err)
+		}
+
+		if conflict.Latest.GetSequenceNumber() != 1 || conflict.Latest.Name != "bob" {
+			return fmt.Errorf("unexpected latest in conflict: %d %s", conflict.Latest.GetSequenceNumber(), conflict.Latest.Name)
+		}
+
+		// a competing write during the first attempt forces a retry
+		attempts := 0
+		updated, err := accounts.UpdateWithRetry(ctx, account.GetPrefix(), func(latest *Account) error {
+			attempts++
+
+			if attempts == 1 {
+				competitor := &Account{}
+				if err := accounts.GetLatestByPrefix(ctx, competitor, account.GetPrefix()); err != nil {
+					return err
+				}
+
+				competitor.Name = "carol"
+				if err := accounts.CreateVersion(ctx, competitor); err != nil {
+					return err
+				}
+			}
+
+			latest.Name += " and dave"
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if attempts != 2 || updated.GetSequenceNumber() != 3 || updated.Name != "carol and dave" {
+			return fmt.Errorf("unexpected retry: %d attempts, %d %s", attempts, updated.GetSequenceNumber(), updated.Name)
+		}
+
+		return nil
+	})
+}
+
+func withPanic(ctx context.Context, store data.Store, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+
+	return data.WithTransaction(ctx, store, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+
+		panic("boom")
+	})
+}
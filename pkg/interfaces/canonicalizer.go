@@ -0,0 +1,6 @@
+package interfaces
+
+// produces the bytes that are hashed and signed for a record
+type Canonicalizer interface {
+	Canonicalize(v any) ([]byte, error)
+}
@@ -0,0 +1,23 @@
+package examples
+
+import (
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+)
+
+// blake2b with a 32 byte digest (rfc 7693)
+type Blake2b_256 struct{}
+
+func NewBlake2b_256() *Blake2b_256 {
+	return &Blake2b_256{}
+}
+
+func (*Blake2b_256) Code() string {
+	return cesr.Blake2b_256
+}
+
+func (b *Blake2b_256) Sum(message string) string {
+	sum := blake2b.Sum256([]byte(message))
+	return cesr.MustDigest(b.Code(), sum[:])
+}
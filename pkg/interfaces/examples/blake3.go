@@ -1,9 +1,9 @@
 package examples
 
 import (
-	"encoding/base64"
-
 	"github.com/zeebo/blake3"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
 )
 
 type Blake3 struct{}
@@ -12,12 +12,11 @@ func NewBlake3() *Blake3 {
 	return &Blake3{}
 }
 
-func (*Blake3) Sum(message string) string {
-	bytes := make([]byte, 33)
-	sum := blake3.Sum256([]byte(message))
-	copy(bytes[1:33], sum[0:32])
-	runes := []rune(base64.URLEncoding.EncodeToString(bytes))
-	runes[0] = 'E'
+func (*Blake3) Code() string {
+	return cesr.Blake3_256
+}
 
-	return string(runes)
+func (b *Blake3) Sum(message string) string {
+	sum := blake3.Sum256([]byte(message))
+	return cesr.MustDigest(b.Code(), sum[:])
 }
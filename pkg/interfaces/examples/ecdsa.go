@@ -0,0 +1,209 @@
+package examples
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+)
+
+// the curve, digest and cesr codes of each supported nist curve
+type ecdsaCurve struct {
+	curve               elliptic.Curve
+	digest              func([]byte) []byte
+	keyCode             string
+	transferableKeyCode string // accepted when verifying, though keys are generated non-transferable
+	signatureCode       string
+}
+
+var (
+	p256 = ecdsaCurve{
+		curve: elliptic.P256(),
+		digest: func(message []byte) []byte {
+			sum := sha256.Sum256(message)
+			return sum[:]
+		},
+		keyCode:             cesr.ECDSA256r1N,
+		transferableKeyCode: cesr.ECDSA256r1,
+		signatureCode:       cesr.ECDSA256r1Sig,
+	}
+
+	p384 = ecdsaCurve{
+		curve: elliptic.P384(),
+		digest: func(message []byte) []byte {
+			sum := sha512.Sum384(message)
+			return sum[:]
+		},
+		keyCode:             cesr.ModuleECDSA384r1N,
+		transferableKeyCode: cesr.ModuleECDSA384r1,
+		signatureCode:       cesr.ModuleECDSA384r1Sig,
+	}
+)
+
+// transferable and non-transferable keys are treated alike on every curve
+func curveForKey(keyCode string) (ecdsaCurve, bool) {
+	for _, curve := range []ecdsaCurve{p256, p384} {
+		if keyCode == curve.keyCode || keyCode == curve.transferableKeyCode {
+			return curve, true
+		}
+	}
+
+	return ecdsaCurve{}, false
+}
+
+func (c ecdsaCurve) size() int {
+	return (c.curve.Params().BitSize + 7) / 8
+}
+
+// (r, s) and (r, n-s) are both valid, so only the lower s is produced or accepted. otherwise anyone
+// could derive a second signature over the same record.
+func (c ecdsaCurve) lowS(s *big.Int) bool {
+	return s.Cmp(new(big.Int).Rsh(c.curve.Params().N, 1)) <= 0
+}
+
+type ECDSAVerificationKey struct {
+	publicKey string
+}
+
+func NewECDSAVerificationKey(publicKey string) *ECDSAVerificationKey {
+	return &ECDSAVerificationKey{
+		publicKey: publicKey,
+	}
+}
+
+func (e ECDSAVerificationKey) Public() (string, error) {
+	return e.publicKey, nil
+}
+
+func (e ECDSAVerificationKey) Verifier() interfaces.Verifier {
+	return NewECDSAVerifier()
+}
+
+// verifies P-256 and P-384 signatures, selecting the curve from the key's code
+type ECDSAVerifier struct{}
+
+func NewECDSAVerifier() *ECDSAVerifier {
+	return &ECDSAVerifier{}
+}
+
+func (e ECDSAVerifier) Verify(signature, publicKey string, message []byte) error {
+	verfer, err := cesr.ParseVerfer(publicKey)
+	if err != nil {
+		return err
+	}
+
+	curve, found := curveForKey(verfer.Code())
+	if !found {
+		return fmt.Errorf("%w: %s is not a nist ecdsa key", cesr.ErrUnexpectedCode, verfer.Code())
+	}
+
+	siger, err := cesr.ParseSiger(signature)
+	if err != nil {
+		return err
+	}
+
+	if siger.Code() != curve.signatureCode {
+		return fmt.Errorf("%w: %s is not a %s signature", cesr.ErrUnexpectedCode, siger.Code(), curve.curve.Params().Name)
+	}
+
+	x, y := elliptic.UnmarshalCompressed(curve.curve, verfer.Raw())
+	if x == nil {
+		return fmt.Errorf("invalid %s public key", curve.curve.Params().Name)
+	}
+
+	raw := siger.Raw()
+	r := new(big.Int).SetBytes(raw[:curve.size()])
+	s := new(big.Int).SetBytes(raw[curve.size():])
+
+	if !curve.lowS(s) {
+		return fmt.Errorf("invalid signature: s is not in the lower half of the curve order")
+	}
+
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: curve.curve, X: x, Y: y}, curve.digest(message), r, s) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	return nil
+}
+
+type ECDSA struct {
+	curve      ecdsaCurve
+	privateKey *ecdsa.PrivateKey
+}
+
+// pass a nil private key to generate one
+func NewECDSAP256(privateKey *ecdsa.PrivateKey) (*ECDSA, error) {
+	return newECDSA(p256, privateKey)
+}
+
+// pass a nil private key to generate one
+func NewECDSAP384(privateKey *ecdsa.PrivateKey) (*ECDSA, error) {
+	return newECDSA(p384, privateKey)
+}
+
+func newECDSA(curve ecdsaCurve, privateKey *ecdsa.PrivateKey) (*ECDSA, error) {
+	if privateKey == nil {
+		var err error
+		privateKey, err = ecdsa.GenerateKey(curve.curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if privateKey.Curve != curve.curve {
+		return nil, fmt.Errorf("incorrect key type")
+	}
+
+	return &ECDSA{
+		curve:      curve,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (e ECDSA) Identity() (string, error) {
+	return e.Public()
+}
+
+// signatures are r || s, each left padded to the curve size, with s normalised to the lower half
+func (e ECDSA) Sign(message []byte) (string, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, e.privateKey, e.curve.digest(message))
+	if err != nil {
+		return "", err
+	}
+
+	if !e.curve.lowS(s) {
+		s.Sub(e.curve.curve.Params().N, s)
+	}
+
+	raw := make([]byte, 2*e.curve.size())
+	r.FillBytes(raw[:e.curve.size()])
+	s.FillBytes(raw[e.curve.size():])
+
+	siger, err := cesr.NewSiger(e.curve.signatureCode, raw)
+	if err != nil {
+		return "", err
+	}
+
+	return siger.Qb64(), nil
+}
+
+func (e ECDSA) Verifier() interfaces.Verifier {
+	return NewECDSAVerifier()
+}
+
+func (e ECDSA) Public() (string, error) {
+	compressed := elliptic.MarshalCompressed(e.curve.curve, e.privateKey.X, e.privateKey.Y)
+
+	verfer, err := cesr.NewVerfer(e.curve.keyCode, compressed)
+	if err != nil {
+		return "", err
+	}
+
+	return verfer.Qb64(), nil
+}
@@ -2,10 +2,11 @@ package examples
 
 import (
 	"crypto/ed25519"
-	"encoding/base64"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 )
 
@@ -34,19 +35,25 @@ func NewEd25519Verifier() *Ed25519Verifier {
 }
 
 func (e Ed25519Verifier) Verify(signature, publicKey string, message []byte) error {
-	keyBytes, err := base64.URLEncoding.DecodeString(publicKey)
+	verfer, err := cesr.ParseVerfer(publicKey)
 	if err != nil {
 		return err
 	}
 
-	edKey := ed25519.PublicKey(keyBytes[1:])
+	if verfer.Code() != cesr.Ed25519N && verfer.Code() != cesr.Ed25519 {
+		return fmt.Errorf("%w: %s is not an ed25519 key", cesr.ErrUnexpectedCode, verfer.Code())
+	}
 
-	sigBytes, err := base64.URLEncoding.DecodeString(signature)
+	siger, err := cesr.ParseSiger(signature)
 	if err != nil {
 		return err
 	}
 
-	if !ed25519.Verify(edKey, message, sigBytes[2:]) {
+	if siger.Code() != cesr.Ed25519Sig {
+		return fmt.Errorf("%w: %s is not an ed25519 signature", cesr.ErrUnexpectedCode, siger.Code())
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(verfer.Raw()), message, siger.Raw()) {
 		return fmt.Errorf("invalid signature")
 	}
 
@@ -89,17 +96,12 @@ func (e Ed25519) Identity() (string, error) {
 }
 
 func (e Ed25519) Sign(message []byte) (string, error) {
-	sig := ed25519.Sign(e.signingKey, message)
-	buffer := [66]byte{}
-
-	copy(buffer[2:], sig)
-
-	b64 := base64.URLEncoding.EncodeToString(buffer[:])
-	qb64 := []rune(b64)
-	qb64[0] = '0'
-	qb64[1] = 'B'
+	siger, err := cesr.NewSiger(cesr.Ed25519Sig, ed25519.Sign(e.signingKey, message))
+	if err != nil {
+		return "", err
+	}
 
-	return string(qb64), nil
+	return siger.Qb64(), nil
 }
 
 func (e Ed25519) Verifier() interfaces.Verifier {
@@ -107,24 +109,31 @@ func (e Ed25519) Verifier() interfaces.Verifier {
 }
 
 func (e Ed25519) Public() (string, error) {
-	buffer := [33]byte{}
-
-	copy(buffer[1:], e.publicKey)
-	b64 := base64.URLEncoding.EncodeToString(buffer[:])
-	qb64 := []rune(b64)
-	qb64[0] = 'B'
+	verfer, err := cesr.NewVerfer(cesr.Ed25519N, e.publicKey)
+	if err != nil {
+		return "", err
+	}
 
-	return string(qb64), nil
+	return verfer.Qb64(), nil
 }
 
 type VerificationKeyStore struct {
-	mu   sync.RWMutex
-	keys map[string]interfaces.VerificationKey
+	mu        sync.RWMutex
+	keys      map[string]interfaces.VerificationKey
+	validity  map[string]interfaces.KeyValidity
+	verifiers map[string]interfaces.Verifier
 }
 
+// signatures are verified by code with the example verifiers, whatever key type they are stored as
 func NewVerificationKeyStore() *VerificationKeyStore {
 	return &VerificationKeyStore{
-		keys: make(map[string]interfaces.VerificationKey),
+		keys:     make(map[string]interfaces.VerificationKey),
+		validity: make(map[string]interfaces.KeyValidity),
+		verifiers: map[string]interfaces.Verifier{
+			cesr.Ed25519Sig:          NewEd25519Verifier(),
+			cesr.ECDSA256r1Sig:       NewECDSAVerifier(),
+			cesr.ModuleECDSA384r1Sig: NewECDSAVerifier(),
+		},
 	}
 }
 
@@ -145,3 +154,48 @@ func (s *VerificationKeyStore) Get(identity string) (interfaces.VerificationKey,
 	}
 	return key, nil
 }
+
+// verifies signatures with the given code in this store, in place of the default
+func (s *VerificationKeyStore) SetVerifier(signatureCode string, verifier interfaces.Verifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.verifiers[signatureCode] = verifier
+}
+
+func (s *VerificationKeyStore) Verifier(signatureCode string) (interfaces.Verifier, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	verifier, exists := s.verifiers[signatureCode]
+	return verifier, exists
+}
+
+// records created outside the window no longer verify against the identity's key
+func (s *VerificationKeyStore) SetValidity(identity string, validity interfaces.KeyValidity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.validity[identity] = validity
+}
+
+// ends the identity's window at when, unless it already ends earlier
+func (s *VerificationKeyStore) Revoke(identity string, when time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	validity := s.validity[identity]
+	if validity.NotAfter.IsZero() || when.Before(validity.NotAfter) {
+		validity.NotAfter = when
+	}
+
+	s.validity[identity] = validity
+}
+
+// unbounded unless set or revoked
+func (s *VerificationKeyStore) Validity(identity string) (interfaces.KeyValidity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.validity[identity], nil
+}
@@ -2,7 +2,8 @@ package examples
 
 import (
 	"crypto/rand"
-	"encoding/base64"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
 )
 
 type Noncer struct{}
@@ -12,17 +13,17 @@ func NewNoncer() *Noncer {
 }
 
 func (*Noncer) Generate() (string, error) {
-	entropy := [18]byte{}
+	entropy := [16]byte{}
 
-	_, err := rand.Read(entropy[2:])
+	_, err := rand.Read(entropy[:])
 	if err != nil {
 		return "", err
 	}
 
-	salt := base64.URLEncoding.EncodeToString(entropy[:])
-	runes := []rune(salt)
-	runes[0] = '0'
-	runes[1] = 'A'
+	salter, err := cesr.NewSalter(cesr.Salt128, entropy[:])
+	if err != nil {
+		return "", err
+	}
 
-	return string(runes), nil
+	return salter.Qb64(), nil
 }
@@ -0,0 +1,22 @@
+package examples
+
+import (
+	"crypto/sha256"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+)
+
+type Sha256 struct{}
+
+func NewSha256() *Sha256 {
+	return &Sha256{}
+}
+
+func (*Sha256) Code() string {
+	return cesr.SHA2_256
+}
+
+func (s *Sha256) Sum(message string) string {
+	sum := sha256.Sum256([]byte(message))
+	return cesr.MustDigest(s.Code(), sum[:])
+}
@@ -0,0 +1,22 @@
+package examples
+
+import (
+	"crypto/sha3"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+)
+
+type Sha3_256 struct{}
+
+func NewSha3_256() *Sha3_256 {
+	return &Sha3_256{}
+}
+
+func (*Sha3_256) Code() string {
+	return cesr.SHA3_256
+}
+
+func (s *Sha3_256) Sum(message string) string {
+	sum := sha3.Sum256([]byte(message))
+	return cesr.MustDigest(s.Code(), sum[:])
+}
@@ -0,0 +1,25 @@
+package examples
+
+import (
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/cesr"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+)
+
+// returns a verification key of the right type for any public key these examples produce
+func ParseVerificationKey(publicKey string) (interfaces.VerificationKey, error) {
+	verfer, err := cesr.ParseVerfer(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch verfer.Code() {
+	case cesr.Ed25519N, cesr.Ed25519:
+		return NewEd25519VerificationKey(publicKey), nil
+	case cesr.ECDSA256r1N, cesr.ECDSA256r1, cesr.ModuleECDSA384r1N, cesr.ModuleECDSA384r1:
+		return NewECDSAVerificationKey(publicKey), nil
+	default:
+		return nil, fmt.Errorf("%w: no example key for %s", cesr.ErrUnexpectedCode, verfer.Code())
+	}
+}
@@ -1,5 +1,7 @@
 package interfaces
 
 type Hasher interface {
+	// the CESR derivation code that begins every digest this hasher produces
+	Code() string
 	Sum(message string) string
 }
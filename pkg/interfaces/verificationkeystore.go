@@ -1,5 +1,56 @@
 package interfaces
 
+import (
+	"context"
+	"time"
+)
+
 type VerificationKeyStore interface {
 	Get(identity string) (VerificationKey, error)
 }
+
+// implemented by key stores that choose the verifier for each signature code, rather than leaving
+// it to the stored key. the key must still be of the type that produces signatures with that code.
+type DispatchingVerificationKeyStore interface {
+	VerificationKeyStore
+	Verifier(signatureCode string) (Verifier, bool)
+}
+
+// implemented by key stores whose identities outlive their keys, like a key event log. the key is
+// the one the identity held when the record was created (when is nil for a record without a
+// timestamp), and the lookup takes part in the read's transaction through ctx. every version of a
+// chain must be signed under the identity that signed its first version.
+type HistoricalVerificationKeyStore interface {
+	VerificationKeyStore
+	GetAt(ctx context.Context, identity string, when *time.Time) (VerificationKey, error)
+}
+
+// implemented by key stores that know when each identity's key could sign. a signature on a record
+// created outside the window fails verification, as does one on a record without a timestamp
+// unless the window is unbounded.
+type TimedVerificationKeyStore interface {
+	VerificationKeyStore
+	Validity(identity string) (KeyValidity, error)
+}
+
+// both ends are inclusive, and a zero time leaves that end open
+type KeyValidity struct {
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+func (v KeyValidity) Bounded() bool {
+	return !v.NotBefore.IsZero() || !v.NotAfter.IsZero()
+}
+
+func (v KeyValidity) Contains(when time.Time) bool {
+	if !v.NotBefore.IsZero() && when.Before(v.NotBefore) {
+		return false
+	}
+
+	if !v.NotAfter.IsZero() && when.After(v.NotAfter) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,9 @@
+package kel
+
+import "errors"
+
+var (
+	ErrInvalidKeyEventLog = errors.New("invalid key event log")
+	ErrUncommittedKey     = errors.New("key was not committed to by the previous event")
+	ErrUntrustedIdentity  = errors.New("identity is not trusted")
+)
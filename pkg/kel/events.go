@@ -0,0 +1,32 @@
+package kel
+
+import "github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+
+const (
+	Inception = "icp"
+	Rotation  = "rot"
+)
+
+// an establishment event in an identity's key event log. the inception event is self-addressed
+// like any first record, so its id is the identity's prefix. every event is signed by the key it
+// establishes (its signing identity is that key) and commits to the digest of the key that may
+// establish the next, so only the holder of that pre-committed key can rotate.
+type KeyEvent struct {
+	primitives.SignableRecorder
+	Kind          string `db:"kind" json:"kind"`
+	NextKeyDigest string `db:"next_key_digest" json:"nextKeyDigest"`
+}
+
+func (*KeyEvent) TableName() string {
+	return `key_events`
+}
+
+// the key this event establishes
+func (e KeyEvent) PublicKey() string {
+	return e.SigningIdentity
+}
+
+// an event without a next key digest ends the log, as no key can follow it
+func (e KeyEvent) Abandoned() bool {
+	return e.NextKeyDigest == ""
+}
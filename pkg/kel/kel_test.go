@@ -0,0 +1,636 @@
+package kel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	storage "github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	data "github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/kel"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+type Document struct {
+	primitives.SignableRecorder
+	Body string `db:"body" json:"body"`
+}
+
+func (*Document) TableName() string {
+	return `document`
+}
+
+func TestKeyEventLog(t *testing.T) {
+	if err := testKeyEventLog(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testKeyEventLog() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	options := schema.Options{Nonce: true, Timestamp: true}
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*Document](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, examples.NewNoncer(), examples.ParseVerificationKey)
+
+	keys := []interfaces.SigningKey{}
+	publicKeys := []string{}
+	for range 3 {
+		key, err := examples.NewECDSAP256(nil)
+		if err != nil {
+			return err
+		}
+
+		publicKey, err := key.Public()
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	inception, err := log.Incept(ctx, keys[0], publicKeys[1])
+	if err != nil {
+		return err
+	}
+
+	prefix := inception.Id
+	log.Trust(prefix)
+
+	document := &Document{Body: "signed before rotation"}
+	if err := createDocument(ctx, store, log, inception, keys[0], document); err != nil {
+		return err
+	}
+
+	if _, err := log.Rotate(ctx, prefix, keys[2], publicKeys[0]); !errors.Is(err, kel.ErrUncommittedKey) {
+		return fmt.Errorf("expected an uncommitted key to be refused: %v", err)
+	}
+
+	// timestamps are stored to the millisecond, and a record created in the same one as a rotation
+	// verifies against the rotated in key
+	time.Sleep(5 * time.Millisecond)
+
+	rotation, err := log.Rotate(ctx, prefix, keys[1], publicKeys[2])
+	if err != nil {
+		return err
+	}
+
+	if rotation.Prefix != prefix || rotation.SequenceNumber != 1 || rotation.Kind != kel.Rotation {
+		return fmt.Errorf("unexpected rotation: %+v", rotation)
+	}
+
+	// the old key no longer establishes anything new
+	if _, err := kel.NewSigningKey(rotation, keys[0]); err == nil {
+		return fmt.Errorf("expected the rotated out key to be refused")
+	}
+
+	if err := createDocument(ctx, store, log, rotation, keys[1], document); err != nil {
+		return err
+	}
+
+	// both versions verify, each against the key that was current when it was signed
+	reader := repository.NewSignableRepository[*Document](store, false, true, examples.NewNoncer(), nil, log)
+
+	documents := []*Document{}
+	if err := reader.ListByPrefix(ctx, &documents, document.Prefix); err != nil {
+		return err
+	}
+
+	if len(documents) != 2 || documents[0].SigningIdentity != prefix || documents[1].SigningIdentity != prefix {
+		return fmt.Errorf("unexpected documents: %+v", documents)
+	}
+
+	for i, expected := range []*kel.KeyEvent{inception, rotation} {
+		established, err := log.EstablishedAt(ctx, prefix, createdAt(documents[i]))
+		if err != nil {
+			return err
+		}
+
+		if established.Id != expected.Id {
+			return fmt.Errorf("expected version %d to be signed under %s: %s", i, expected.Id, established.Id)
+		}
+	}
+
+	// a log that doesn't trust the identity verifies nothing it signed
+	untrusting := repository.NewSignableRepository[*Document](
+		store, false, true, examples.NewNoncer(), nil,
+		kel.NewKeyEventLog(store, examples.NewNoncer(), examples.ParseVerificationKey),
+	)
+
+	if err := untrusting.GetById(ctx, &Document{}, document.Id); !errors.Is(err, kel.ErrUntrustedIdentity) {
+		return fmt.Errorf("expected an untrusted identity to be rejected: %v", err)
+	}
+
+	// the rotated out key can no longer sign under the identity
+	time.Sleep(5 * time.Millisecond)
+
+	leaked := &Document{Body: "signed after rotation"}
+	if err := createDocument(ctx, store, log, inception, keys[0], leaked); err != nil {
+		return err
+	}
+
+	if err := reader.GetById(ctx, &Document{}, leaked.Id); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected a signature by the rotated out key to be rejected: %v", err)
+	}
+
+	// a rotation that rolls back is forgotten along with its transaction
+	rolledBack := errors.New("rolled back")
+	if err := storage.WithTransaction(ctx, store, func(ctx context.Context) error {
+		if _, err := log.Rotate(ctx, prefix, keys[2], publicKeys[0]); err != nil {
+			return err
+		}
+
+		latest, err := log.Latest(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		if latest.SequenceNumber != 2 {
+			return fmt.Errorf("expected the rotation to be visible in its transaction: %+v", latest)
+		}
+
+		return rolledBack
+	}); !errors.Is(err, rolledBack) {
+		return err
+	}
+
+	latest, err := log.Latest(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if latest.Id != rotation.Id {
+		return fmt.Errorf("expected the rolled back rotation to be forgotten: %+v", latest)
+	}
+
+	// the final rotation abandons the identity
+	if _, err := log.Rotate(ctx, prefix, keys[2], ""); err != nil {
+		return err
+	}
+
+	if _, err := log.Rotate(ctx, prefix, keys[0], ""); !errors.Is(err, kel.ErrUncommittedKey) {
+		return fmt.Errorf("expected rotation of an abandoned identity to be refused: %v", err)
+	}
+
+	return nil
+}
+
+func TestForgedRotation(t *testing.T) {
+	if err := testForgedRotation(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testForgedRotation() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), schema.Options{Timestamp: true}); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, nil, examples.ParseVerificationKey)
+
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	next, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	nextPublicKey, err := next.Public()
+	if err != nil {
+		return err
+	}
+
+	inception, err := log.Incept(ctx, key, nextPublicKey)
+	if err != nil {
+		return err
+	}
+
+	// an attacker writing directly to the table can sign a rotation to their own key, but can't
+	// match the commitment
+	attacker, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	attackerIdentity, err := attacker.Identity()
+	if err != nil {
+		return err
+	}
+
+	keyStore := examples.NewVerificationKeyStore()
+	keyStore.Add(attackerIdentity, attacker)
+
+	forger := repository.NewSignableRepository[*kel.KeyEvent](store, true, true, nil, attacker, keyStore)
+
+	forged := *inception
+	forged.Kind = kel.Rotation
+	if err := forger.CreateVersion(ctx, &forged); err != nil {
+		return err
+	}
+
+	log.Trust(inception.Prefix)
+
+	if _, err := log.Get(inception.Prefix); !errors.Is(err, kel.ErrInvalidKeyEventLog) || !errors.Is(err, kel.ErrUncommittedKey) {
+		return fmt.Errorf("expected the forged rotation to be rejected: %v", err)
+	}
+
+	if _, err := log.Latest(ctx, inception.Prefix); !errors.Is(err, kel.ErrInvalidKeyEventLog) {
+		return fmt.Errorf("expected the forged log to be rejected: %v", err)
+	}
+
+	nothing := algorithms.Digest("nothing", nil)
+	if _, err := log.Get(nothing); !errors.Is(err, kel.ErrUntrustedIdentity) {
+		return fmt.Errorf("expected an untrusted identity to be rejected: %v", err)
+	}
+
+	log.Trust(nothing)
+	if _, err := log.Get(nothing); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("expected an unknown identity to be missing: %v", err)
+	}
+
+	return nil
+}
+
+func TestUnverifiedEvents(t *testing.T) {
+	if err := testUnverifiedEvents(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+// events are always verified, and only verified events are remembered, whatever the caller's policy
+func testUnverifiedEvents() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), schema.Options{Timestamp: true}); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, nil, examples.ParseVerificationKey)
+
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	next, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	nextPublicKey, err := next.Public()
+	if err != nil {
+		return err
+	}
+
+	inception, err := log.Incept(ctx, key, nextPublicKey)
+	if err != nil {
+		return err
+	}
+
+	log.Trust(inception.Prefix)
+
+	rotation, err := log.Rotate(ctx, inception.Prefix, next, "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(
+		ctx,
+		`UPDATE key_events SET next_key_digest = ? WHERE id = ?`,
+		algorithms.Digest("tampered", nil),
+		rotation.Id,
+	); err != nil {
+		return err
+	}
+
+	unverified := repository.WithVerificationPolicy(ctx, repository.VerifyOff)
+	if _, err := log.Latest(unverified, inception.Prefix); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected the tampered event to be verified: %v", err)
+	}
+
+	if _, err := log.Latest(ctx, inception.Prefix); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected the tampered event not to be remembered: %v", err)
+	}
+
+	return nil
+}
+
+func TestEventTimestamps(t *testing.T) {
+	if err := testEventTimestamps(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+// keys are chosen by time, so a log must not go back in time
+func testEventTimestamps() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), schema.Options{Timestamp: true}); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, nil, examples.ParseVerificationKey)
+
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	next, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	nextPublicKey, err := next.Public()
+	if err != nil {
+		return err
+	}
+
+	// events written directly, rather than through the log, carry whatever timestamps they are given
+	writer := func(key interfaces.SigningKey) *repository.SignableRepository[*kel.KeyEvent] {
+		return repository.NewSignableRepository[*kel.KeyEvent](store, true, false, nil, key, examples.NewVerificationKeyStore())
+	}
+
+	now := primitives.Timestamp(time.Now())
+	inception := &kel.KeyEvent{Kind: kel.Inception, NextKeyDigest: algorithms.Digest(nextPublicKey, nil)}
+	inception.StampCreatedAt(&now)
+	if err := writer(key).CreateVersion(ctx, inception); err != nil {
+		return err
+	}
+
+	// the holder of the next key backdates their rotation, to take over records signed before it
+	earlier := primitives.Timestamp(time.Now().Add(-time.Hour))
+	rotation := *inception
+	rotation.Kind = kel.Rotation
+	rotation.NextKeyDigest = ""
+	rotation.StampCreatedAt(&earlier)
+	if err := writer(next).CreateVersion(ctx, &rotation); !errors.Is(err, repository.ErrBackdated) {
+		return fmt.Errorf("expected the backdated rotation to be refused: %v", err)
+	}
+
+	// so they write it to the table themselves
+	preparer := repository.NewSignableRepository[*kel.KeyEvent](store, false, false, nil, next, examples.NewVerificationKeyStore())
+	if err := preparer.CreateVersion(ctx, &rotation); err != nil {
+		return err
+	}
+
+	columns := []string{"id", "prefix", "previous", "sequence_number", "created_at", "signing_identity", "signature", "kind", "next_key_digest"}
+	if _, err := store.Sql(ctx).NamedExecContext(ctx, store.Dialect().Insert("key_events", columns), &rotation); err != nil {
+		return err
+	}
+
+	log.Trust(inception.Prefix)
+
+	if _, err := log.Latest(ctx, inception.Prefix); !errors.Is(err, kel.ErrInvalidKeyEventLog) {
+		return fmt.Errorf("expected a log going back in time to be rejected: %v", err)
+	}
+
+	// a table created without timestamps can't place any event in time
+	untimedStore, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*kel.KeyEvent](ctx, untimedStore, untimedStore.Dialect(), schema.Options{}); err != nil {
+		return err
+	}
+
+	untimed := &kel.KeyEvent{Kind: kel.Inception}
+	untimedWriter := repository.NewSignableRepository[*kel.KeyEvent](untimedStore, true, false, nil, key, examples.NewVerificationKeyStore())
+	if err := untimedWriter.CreateVersion(ctx, untimed); err != nil {
+		return err
+	}
+
+	log = kel.NewKeyEventLog(untimedStore, nil, examples.ParseVerificationKey)
+
+	log.Trust(untimed.Prefix)
+
+	if _, err := log.Latest(ctx, untimed.Prefix); !errors.Is(err, kel.ErrInvalidKeyEventLog) {
+		return fmt.Errorf("expected an event without a timestamp to be rejected: %v", err)
+	}
+
+	return nil
+}
+
+func TestHijackedDocument(t *testing.T) {
+	if err := testHijackedDocument(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testHijackedDocument() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	options := schema.Options{Nonce: true, Timestamp: true}
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*Document](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, examples.NewNoncer(), examples.ParseVerificationKey)
+
+	owner, ownerInception, err := incept(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	log.Trust(ownerInception.Prefix)
+
+	document := &Document{Body: "the owner's"}
+	if err := createDocument(ctx, store, log, ownerInception, owner, document); err != nil {
+		return err
+	}
+
+	// anyone can incept an identity of their own, and sign a version onto the owner's document with it
+	attacker, attackerInception, err := incept(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	hijacked := *document
+	hijacked.Body = "the attacker's"
+	if err := createDocument(ctx, store, log, attackerInception, attacker, &hijacked); err != nil {
+		return err
+	}
+
+	reader := repository.NewSignableRepository[*Document](store, false, true, examples.NewNoncer(), nil, log)
+
+	if err := reader.GetLatestByPrefix(ctx, &Document{}, document.Prefix); !errors.Is(err, kel.ErrUntrustedIdentity) {
+		return fmt.Errorf("expected a version signed by an untrusted identity to be rejected: %v", err)
+	}
+
+	// trusting the attacker for their own records doesn't give them the owner's
+	log.Trust(attackerInception.Prefix)
+
+	if err := reader.GetLatestByPrefix(ctx, &Document{}, document.Prefix); !errors.Is(err, repository.ErrNotController) {
+		return fmt.Errorf("expected a version signed by another identity to be rejected: %v", err)
+	}
+
+	documents := []*Document{}
+	if err := reader.ListByPrefix(ctx, &documents, document.Prefix); !errors.Is(err, repository.ErrNotController) {
+		return fmt.Errorf("expected the hijacked chain to be rejected: %v", err)
+	}
+
+	return nil
+}
+
+func TestVerifyInTransaction(t *testing.T) {
+	if err := testVerifyInTransaction(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+// the in-memory store has a single connection, so key lookups must share the read's transaction
+func testVerifyInTransaction() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	options := schema.Options{Nonce: true, Timestamp: true}
+	if err := schema.Apply[*kel.KeyEvent](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*Document](ctx, store, store.Dialect(), options); err != nil {
+		return err
+	}
+
+	log := kel.NewKeyEventLog(store, examples.NewNoncer(), examples.ParseVerificationKey)
+
+	key, inception, err := incept(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	log.Trust(inception.Prefix)
+
+	document := &Document{Body: "first"}
+	if err := createDocument(ctx, store, log, inception, key, document); err != nil {
+		return err
+	}
+
+	document.Body = "second"
+	if err := createDocument(ctx, store, log, inception, key, document); err != nil {
+		return err
+	}
+
+	reader := repository.NewSignableRepository[*Document](store, false, true, examples.NewNoncer(), nil, log)
+
+	return storage.WithTransaction(ctx, store, func(ctx context.Context) error {
+		if err := reader.GetById(ctx, &Document{}, document.Id); err != nil {
+			return err
+		}
+
+		count := 0
+		for _, err := range reader.SelectIter(ctx, expressions.Equal("prefix", document.Prefix), orderings.Ascending("sequence_number"), nil) {
+			if err != nil {
+				return err
+			}
+
+			count++
+		}
+
+		if count != 2 {
+			return fmt.Errorf("expected 2 documents: %d", count)
+		}
+
+		return nil
+	})
+}
+
+func incept(ctx context.Context, log *kel.KeyEventLog) (interfaces.SigningKey, *kel.KeyEvent, error) {
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inception, err := log.Incept(ctx, key, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, inception, nil
+}
+
+func createdAt(document *Document) *time.Time {
+	when := time.Time(*document.CreatedAt)
+	return &when
+}
+
+func createDocument(
+	ctx context.Context,
+	store *data.SQLiteStore,
+	log *kel.KeyEventLog,
+	event *kel.KeyEvent,
+	key interfaces.SigningKey,
+	document *Document,
+) error {
+	signingKey, err := kel.NewSigningKey(event, key)
+	if err != nil {
+		return err
+	}
+
+	writer := repository.NewSignableRepository[*Document](store, true, true, examples.NewNoncer(), signingKey, log)
+
+	return writer.CreateVersion(ctx, document)
+}
@@ -0,0 +1,345 @@
+package kel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/clauses"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// key event logs for any number of identities, stored through a SignableRepository. the log is
+// also a VerificationKeyStore for the identities it trusts: records signed with a SigningKey name
+// the identity's prefix, and verify against the key the identity held when they were created.
+type KeyEventLog struct {
+	store    data.Store
+	noncer   interfaces.Noncer
+	parseKey func(publicKey string) (interfaces.VerificationKey, error)
+
+	events *repository.SignableRepository[*KeyEvent]
+
+	mu      sync.RWMutex
+	trusted map[string]bool
+
+	// events are immutable, so a validated log only needs the events appended since it was read
+	logs map[string][]*KeyEvent
+}
+
+// parseKey turns the public keys events carry into verification keys (examples.ParseVerificationKey
+// handles the example key types). the table must be created with timestamps, and with nonces
+// unless noncer is nil.
+func NewKeyEventLog(
+	store data.Store,
+	noncer interfaces.Noncer,
+	parseKey func(publicKey string) (interfaces.VerificationKey, error),
+) *KeyEventLog {
+	l := &KeyEventLog{
+		store:    store,
+		noncer:   noncer,
+		parseKey: parseKey,
+
+		trusted: map[string]bool{},
+		logs:    map[string][]*KeyEvent{},
+	}
+
+	l.events = repository.NewSignableRepository[*KeyEvent](store, false, true, noncer, nil, publicKeys(parseKey))
+
+	return l
+}
+
+// anyone who can write to the table can incept an identity, so only the logs of trusted prefixes
+// are read. everything else fails with ErrUntrustedIdentity.
+func (l *KeyEventLog) Trust(prefixes ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, prefix := range prefixes {
+		l.trusted[prefix] = true
+	}
+}
+
+// starts the log of a new identity, whose prefix is the returned event's id. pass an empty next
+// public key for an identity that can never rotate. the identity must be trusted before it can
+// rotate or sign.
+func (l *KeyEventLog) Incept(ctx context.Context, key interfaces.SigningKey, nextPublicKey string) (*KeyEvent, error) {
+	event := &KeyEvent{
+		Kind:          Inception,
+		NextKeyDigest: commit(nextPublicKey),
+	}
+
+	if err := l.writer(key).CreateVersion(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// establishes key, which must be the one the latest event committed to, and commits to the next
+func (l *KeyEventLog) Rotate(
+	ctx context.Context,
+	prefix string,
+	key interfaces.SigningKey,
+	nextPublicKey string,
+) (*KeyEvent, error) {
+	event, err := l.Latest(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := key.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCommitment(event, publicKey); err != nil {
+		return nil, err
+	}
+
+	event.Kind = Rotation
+	event.NextKeyDigest = commit(nextPublicKey)
+
+	if err := l.writer(key).CreateVersion(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// the event establishing the identity's current key, after validating the whole log
+func (l *KeyEventLog) Latest(ctx context.Context, prefix string) (*KeyEvent, error) {
+	events, err := l.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return events[len(events)-1], nil
+}
+
+// the event establishing the key the identity held at when: the last one created at or before it.
+// a record created before inception, or without a timestamp, was signed by no key of the identity.
+func (l *KeyEventLog) EstablishedAt(ctx context.Context, prefix string, when *time.Time) (*KeyEvent, error) {
+	events, err := l.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if when == nil {
+		return nil, algorithms.KeyValidityError{Identity: prefix}
+	}
+
+	var established *KeyEvent
+	for _, event := range events {
+		if time.Time(*event.CreatedAt).After(*when) {
+			break
+		}
+
+		established = event
+	}
+
+	if established == nil {
+		return nil, algorithms.KeyValidityError{Identity: prefix, CreatedAt: when}
+	}
+
+	return established, nil
+}
+
+// the identity's current key, for callers without a record or a context. signed reads use GetAt.
+func (l *KeyEventLog) Get(identity string) (interfaces.VerificationKey, error) {
+	event, err := l.Latest(context.Background(), identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parseKey(event.PublicKey())
+}
+
+// the key the identity held when the record was created, read through ctx so that verifying inside
+// a transaction uses it. a key that has been rotated away from can't sign records dated after the
+// rotation.
+func (l *KeyEventLog) GetAt(ctx context.Context, identity string, when *time.Time) (interfaces.VerificationKey, error) {
+	event, err := l.EstablishedAt(ctx, identity, when)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.parseKey(event.PublicKey())
+}
+
+// signs with key under the prefix of the identity whose event established it
+type SigningKey struct {
+	interfaces.SigningKey
+	identity string
+}
+
+func NewSigningKey(event *KeyEvent, key interfaces.SigningKey) (*SigningKey, error) {
+	publicKey, err := key.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	if publicKey != event.PublicKey() {
+		return nil, fmt.Errorf("key %s was not established by %s", publicKey, event.Id)
+	}
+
+	return &SigningKey{
+		SigningKey: key,
+		identity:   event.Prefix,
+	}, nil
+}
+
+func (k SigningKey) Identity() (string, error) {
+	return k.identity, nil
+}
+
+// helpers
+
+// events are signed by the keys they establish, so the signing identity must be the public key
+func (l *KeyEventLog) writer(key interfaces.SigningKey) *repository.SignableRepository[*KeyEvent] {
+	return repository.NewSignableRepository[*KeyEvent](
+		l.store,
+		true,
+		true,
+		l.noncer,
+		publicIdentity{key},
+		publicKeys(l.parseKey),
+	)
+}
+
+// lists and validates the identity's log, reading only the events appended since it was last read.
+// events read inside a transaction aren't remembered, as the transaction may yet roll back.
+func (l *KeyEventLog) list(ctx context.Context, prefix string) ([]*KeyEvent, error) {
+	l.mu.RLock()
+	trusted := l.trusted[prefix]
+	cached := l.logs[prefix]
+	l.mu.RUnlock()
+
+	if !trusted {
+		return nil, fmt.Errorf("%w: %s", ErrUntrustedIdentity, prefix)
+	}
+
+	// validate() relies on every event having been verified, and what is read here may be remembered,
+	// so the caller's verification policy never applies
+	appended := []*KeyEvent{}
+	if err := l.events.Select(
+		repository.WithVerificationPolicy(ctx, repository.VerifyStrict),
+		&appended,
+		clauses.And([]data.ClauseOrExpression{
+			expressions.Equal("prefix", prefix),
+			expressions.GreaterThanOrEqual("sequence_number", len(cached)),
+		}),
+		orderings.Ascending("sequence_number"),
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(cached)+len(appended) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	events := append(slices.Clone(cached), appended...)
+	for i := len(cached); i < len(events); i++ {
+		if err := validate(events[:i], events[i]); err != nil {
+			return nil, fmt.Errorf("%w: %s at %d: %w", ErrInvalidKeyEventLog, prefix, events[i].SequenceNumber, err)
+		}
+	}
+
+	if _, ok := data.TransactionFromContext(ctx, l.store); !ok && len(appended) > 0 {
+		l.mu.Lock()
+		if len(events) > len(l.logs[prefix]) {
+			l.logs[prefix] = events
+		}
+		l.mu.Unlock()
+	}
+
+	// copies, so callers are free to modify what they are returned
+	copies := make([]*KeyEvent, len(events))
+	for i, event := range events {
+		copied := *event
+		copies[i] = &copied
+	}
+
+	return copies, nil
+}
+
+// the signatures and self-addresses have already been verified by the repository
+func validate(preceding []*KeyEvent, event *KeyEvent) error {
+	if event.SequenceNumber != uint64(len(preceding)) {
+		return fmt.Errorf("expected sequence number %d", len(preceding))
+	}
+
+	// keys are chosen by time, so the log must be ordered in time as well as by sequence number
+	if event.CreatedAt == nil {
+		return fmt.Errorf("event has no timestamp")
+	}
+
+	if len(preceding) > 0 && time.Time(*event.CreatedAt).Before(time.Time(*preceding[len(preceding)-1].CreatedAt)) {
+		return fmt.Errorf("created before %s", preceding[len(preceding)-1].Id)
+	}
+
+	if len(preceding) == 0 {
+		if event.Kind != Inception {
+			return fmt.Errorf("first event is a %s", event.Kind)
+		}
+
+		return nil
+	}
+
+	if event.Kind != Rotation {
+		return fmt.Errorf("unexpected %s", event.Kind)
+	}
+
+	previous := preceding[len(preceding)-1]
+	if event.Previous == nil || *event.Previous != previous.Id {
+		return fmt.Errorf("does not follow %s", previous.Id)
+	}
+
+	return checkCommitment(previous, event.PublicKey())
+}
+
+func checkCommitment(previous *KeyEvent, publicKey string) error {
+	if previous.Abandoned() {
+		return fmt.Errorf("%w: %s was abandoned", ErrUncommittedKey, previous.Prefix)
+	}
+
+	matches, err := algorithms.DigestMatches(previous.NextKeyDigest, publicKey)
+	if err != nil {
+		return err
+	}
+
+	if !matches {
+		return fmt.Errorf("%w: %s", ErrUncommittedKey, publicKey)
+	}
+
+	return nil
+}
+
+func commit(publicKey string) string {
+	if publicKey == "" {
+		return ""
+	}
+
+	return algorithms.Digest(publicKey, nil)
+}
+
+type publicIdentity struct {
+	interfaces.SigningKey
+}
+
+func (k publicIdentity) Identity() (string, error) {
+	return k.Public()
+}
+
+type publicKeys func(publicKey string) (interfaces.VerificationKey, error)
+
+func (p publicKeys) Get(identity string) (interfaces.VerificationKey, error) {
+	return p(identity)
+}
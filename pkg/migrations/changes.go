@@ -0,0 +1,157 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+// describes how a migration changes the model stored in a verifiable table
+type Change interface {
+	TableName() string
+	// statically compares the models, failing if the hash input of existing rows would change
+	Check() error
+	// loads every row as the new model and reports those that would no longer verify
+	DryRun(ctx context.Context, store data.Store) (*Report, error)
+}
+
+type Report struct {
+	Table    string    `json:"table"`
+	Checked  int       `json:"checked"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+type Failure struct {
+	Id     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type ModelChange[Before, After primitives.VerifiableAndRecordable] struct {
+	canonicalizer interfaces.Canonicalizer
+	hashers       []interfaces.Hasher
+}
+
+func NewModelChange[Before, After primitives.VerifiableAndRecordable]() *ModelChange[Before, After] {
+	return &ModelChange[Before, After]{}
+}
+
+// must match the canonicalizer of the repository that wrote the table
+func (c *ModelChange[Before, After]) SetCanonicalizer(canonicalizer interfaces.Canonicalizer) {
+	c.canonicalizer = canonicalizer
+}
+
+// the hashers, beyond blake3, that records in the table may be addressed with
+func (c *ModelChange[Before, After]) AcceptHashers(hashers ...interfaces.Hasher) {
+	c.hashers = append(c.hashers, hashers...)
+}
+
+func (ModelChange[Before, After]) TableName() string {
+	return reflect.New(reflect.TypeFor[After]().Elem()).Interface().(After).TableName()
+}
+
+func (c ModelChange[Before, After]) Check() error {
+	before := jsonFields(reflect.TypeFor[Before]())
+	after := jsonFields(reflect.TypeFor[After]())
+
+	for name, field := range before {
+		changed, exists := after[name]
+		if !exists {
+			return fmt.Errorf("%w: %s.%s would be removed", ErrUnsafeMigration, c.TableName(), name)
+		}
+
+		if changed.Type != field.Type || changed.OmitEmpty != field.OmitEmpty {
+			return fmt.Errorf("%w: %s.%s would change encoding", ErrUnsafeMigration, c.TableName(), name)
+		}
+	}
+
+	for name, field := range after {
+		if _, exists := before[name]; exists {
+			continue
+		}
+
+		if !field.OmitEmpty {
+			return fmt.Errorf("%w: %s.%s must be omitempty", ErrUnsafeMigration, c.TableName(), name)
+		}
+	}
+
+	return nil
+}
+
+func (c ModelChange[Before, After]) DryRun(ctx context.Context, store data.Store) (*Report, error) {
+	records := []After{}
+
+	query := fmt.Sprintf("SELECT * FROM %s", store.Dialect().QuoteIdentifier(c.TableName()))
+	if err := store.Sql(ctx).SelectContext(ctx, &records, query); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Table:   c.TableName(),
+		Checked: len(records),
+	}
+
+	for _, record := range records {
+		var err error
+		if record.GetSequenceNumber() == 0 {
+			err = algorithms.VerifyPrefixAndData(record, c.canonicalizer, c.hashers...)
+		} else {
+			err = algorithms.VerifyAddressAndData(record, c.canonicalizer, c.hashers...)
+		}
+
+		if err != nil {
+			report.Failures = append(report.Failures, Failure{
+				Id:     record.GetId(),
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	return report, nil
+}
+
+type jsonField struct {
+	Type      reflect.Type
+	OmitEmpty bool
+}
+
+// follows encoding/json's flattening of untagged embedded structs
+func jsonFields(t reflect.Type) map[string]jsonField {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := map[string]jsonField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			for name, nested := range jsonFields(field.Type) {
+				fields[name] = nested
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = jsonField{
+			Type:      field.Type,
+			OmitEmpty: strings.Contains(options, "omitempty"),
+		}
+	}
+
+	return fields
+}
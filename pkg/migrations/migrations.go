@@ -0,0 +1,210 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+var ErrUnsafeMigration = errors.New("unsafe migration")
+
+const MigrationsTable = `verifiable_migrations`
+
+var migrationsTableSql = fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %s (
+	version		BIGINT PRIMARY KEY,
+	name		TEXT NOT NULL,
+	applied_at	TEXT NOT NULL
+);
+`, MigrationsTable)
+
+type Migration struct {
+	Version    uint64
+	Name       string
+	Statements []string
+
+	// describe any verifiable models the statements change so they can be checked before applying
+	Changes []Change
+}
+
+type AppliedMigration struct {
+	Version   uint64 `db:"version"`
+	Name      string `db:"name"`
+	AppliedAt string `db:"applied_at"`
+}
+
+type Migrator struct {
+	store      data.Store
+	migrations []Migration
+}
+
+// migrations must be supplied in strictly increasing version order
+func NewMigrator(store data.Store, migrations []Migration) (*Migrator, error) {
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version <= migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %d is out of order", migrations[i].Version)
+		}
+	}
+
+	return &Migrator{
+		store:      store,
+		migrations: migrations,
+	}, nil
+}
+
+func (m Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	if _, err := m.store.Sql(ctx).ExecContext(ctx, migrationsTableSql); err != nil {
+		return nil, err
+	}
+
+	applied := []AppliedMigration{}
+	query := fmt.Sprintf("SELECT version, name, applied_at FROM %s ORDER BY version ASC", MigrationsTable)
+	if err := m.store.Sql(ctx).SelectContext(ctx, &applied, query); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+func (m Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []uint64{}
+	for _, migration := range applied {
+		versions = append(versions, migration.Version)
+	}
+
+	pending := []Migration{}
+	for _, migration := range m.migrations {
+		if !slices.Contains(versions, migration.Version) {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// applies each pending migration in its own transaction, refusing any that would leave existing
+// records unverifiable
+func (m Migrator) Migrate(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range pending {
+		if err := m.apply(ctx, migration); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// applies all pending migrations inside a transaction that is always rolled back, reporting which
+// existing records would stop verifying
+func (m Migrator) DryRun(ctx context.Context) ([]Report, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = m.store.BeginTransaction(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer m.store.RollbackTransaction(ctx)
+
+	reports := []Report{}
+	for _, migration := range pending {
+		if err := m.execute(ctx, migration); err != nil {
+			return nil, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+
+		for _, change := range migration.Changes {
+			report, err := change.DryRun(ctx, m.store)
+			if err != nil {
+				return nil, err
+			}
+
+			reports = append(reports, *report)
+		}
+	}
+
+	return reports, nil
+}
+
+func (m Migrator) apply(ctx context.Context, migration Migration) error {
+	for _, change := range migration.Changes {
+		if err := change.Check(); err != nil {
+			return err
+		}
+	}
+
+	ctx, err := m.store.BeginTransaction(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := m.applyInTransaction(ctx, migration); err != nil {
+		if rollbackErr := m.store.RollbackTransaction(ctx); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return m.store.CommitTransaction(ctx)
+}
+
+func (m Migrator) applyInTransaction(ctx context.Context, migration Migration) error {
+	if err := m.execute(ctx, migration); err != nil {
+		return err
+	}
+
+	for _, change := range migration.Changes {
+		report, err := change.DryRun(ctx, m.store)
+		if err != nil {
+			return err
+		}
+
+		if len(report.Failures) > 0 {
+			return fmt.Errorf(
+				"%w: %d of %d records in %s would fail verification",
+				ErrUnsafeMigration,
+				len(report.Failures),
+				report.Checked,
+				report.Table,
+			)
+		}
+	}
+
+	query := m.store.Dialect().ReplacePlaceholders(
+		fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", MigrationsTable),
+	)
+
+	now := primitives.Timestamp(time.Now()).UTC()
+	if _, err := m.store.Sql(ctx).ExecContext(ctx, query, migration.Version, migration.Name, now); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m Migrator) execute(ctx context.Context, migration Migration) error {
+	for _, statement := range migration.Statements {
+		if _, err := m.store.Sql(ctx).ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
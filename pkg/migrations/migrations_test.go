@@ -0,0 +1,190 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	data "github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/migrations"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+type ModelV1 struct {
+	primitives.VerifiableRecorder
+	Foo string `db:"foo" json:"foo"`
+}
+
+func (*ModelV1) TableName() string {
+	return `model`
+}
+
+type ModelV2 struct {
+	primitives.VerifiableRecorder
+	Foo string  `db:"foo" json:"foo"`
+	Baz *string `db:"baz" json:"baz,omitempty"`
+}
+
+func (*ModelV2) TableName() string {
+	return `model`
+}
+
+type ModelV3 struct {
+	primitives.VerifiableRecorder
+	Foo string  `db:"foo" json:"foo"`
+	Baz *string `db:"baz" json:"baz,omitempty"`
+	Qux string  `db:"qux" json:"qux"`
+}
+
+func (*ModelV3) TableName() string {
+	return `model`
+}
+
+var MODEL_TABLE_SQL = `
+CREATE TABLE model (
+	id              	TEXT PRIMARY KEY,
+	prefix				TEXT NOT NULL,
+	previous        	TEXT,
+	sequence_number 	BIGINT NOT NULL,
+	created_at          DATETIME NOT NULL,
+	nonce           	TEXT NOT NULL,
+	foo 				TEXT NOT NULL,
+	UNIQUE(prefix, sequence_number)
+);
+`
+
+func TestMigrations(t *testing.T) {
+	if err := testMigrations(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testMigrations() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	initial := migrations.Migration{
+		Version:    1,
+		Name:       "create model",
+		Statements: []string{MODEL_TABLE_SQL},
+	}
+
+	migrator, err := migrations.NewMigrator(store, []migrations.Migration{initial})
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Migrate(ctx); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*ModelV1](store, true, true, examples.NewNoncer())
+	record := &ModelV1{Foo: "bar"}
+	for range 3 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	// a non-omitempty field would change the hash input of every existing row
+	unsafe := migrations.Migration{
+		Version:    2,
+		Name:       "add qux",
+		Statements: []string{`ALTER TABLE model ADD COLUMN baz TEXT`, `ALTER TABLE model ADD COLUMN qux TEXT NOT NULL DEFAULT ''`},
+		Changes:    []migrations.Change{migrations.NewModelChange[*ModelV1, *ModelV3]()},
+	}
+
+	migrator, err = migrations.NewMigrator(store, []migrations.Migration{initial, unsafe})
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Migrate(ctx); !errors.Is(err, migrations.ErrUnsafeMigration) {
+		return fmt.Errorf("expected unsafe migration: %v", err)
+	}
+
+	// the model is safe, but the column default populates old rows
+	defaulted := migrations.Migration{
+		Version:    2,
+		Name:       "add baz",
+		Statements: []string{`ALTER TABLE model ADD COLUMN baz TEXT DEFAULT 'oops'`},
+		Changes:    []migrations.Change{migrations.NewModelChange[*ModelV1, *ModelV2]()},
+	}
+
+	migrator, err = migrations.NewMigrator(store, []migrations.Migration{initial, defaulted})
+	if err != nil {
+		return err
+	}
+
+	reports, err := migrator.DryRun(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(reports) != 1 || reports[0].Checked != 3 || len(reports[0].Failures) != 3 {
+		return fmt.Errorf("unexpected dry run reports: %+v", reports)
+	}
+
+	if err := migrator.Migrate(ctx); !errors.Is(err, migrations.ErrUnsafeMigration) {
+		return fmt.Errorf("expected unsafe migration: %v", err)
+	}
+
+	safe := migrations.Migration{
+		Version:    2,
+		Name:       "add baz",
+		Statements: []string{`ALTER TABLE model ADD COLUMN baz TEXT`},
+		Changes:    []migrations.Change{migrations.NewModelChange[*ModelV1, *ModelV2]()},
+	}
+
+	migrator, err = migrations.NewMigrator(store, []migrations.Migration{initial, safe})
+	if err != nil {
+		return err
+	}
+
+	reports, err = migrator.DryRun(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(reports) != 1 || len(reports[0].Failures) != 0 {
+		return fmt.Errorf("unexpected dry run reports: %+v", reports)
+	}
+
+	if err := migrator.Migrate(ctx); err != nil {
+		return err
+	}
+
+	applied, err := migrator.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) != 2 || applied[1].Version != 2 {
+		return fmt.Errorf("unexpected applied migrations: %+v", applied)
+	}
+
+	// old rows still verify through the new model
+	r2 := repository.NewVerifiableRepository[*ModelV2](store, true, true, examples.NewNoncer())
+	records := []*ModelV2{}
+	if err := r2.ListByPrefix(ctx, &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	if len(records) != 3 {
+		return fmt.Errorf("unexpected record count: %d", len(records))
+	}
+
+	if _, err := migrations.NewMigrator(store, []migrations.Migration{safe, initial}); err == nil {
+		return fmt.Errorf("expected an error for out of order migrations")
+	}
+
+	return nil
+}
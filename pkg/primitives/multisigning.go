@@ -0,0 +1,69 @@
+package primitives
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type MultiSignable interface {
+	SetSignatures(signatures []string)
+	GetSignatures() []string
+	SetSigningIdentities(identities []string)
+	GetSigningIdentities() []string
+}
+
+// signatures are cesr indexed signatures, each carrying the position of its signer in
+// SigningIdentities. only as many signers as the threshold requires need to sign.
+type MultiSigner struct {
+	SigningIdentities StringList `db:"signing_identities" json:"signingIdentities"`
+	Signatures        StringList `db:"signatures" json:"-"`
+}
+
+func (m *MultiSigner) SetSignatures(signatures []string) {
+	m.Signatures = signatures
+}
+
+func (m MultiSigner) GetSignatures() []string {
+	return m.Signatures
+}
+
+func (m *MultiSigner) SetSigningIdentities(identities []string) {
+	m.SigningIdentities = identities
+}
+
+func (m MultiSigner) GetSigningIdentities() []string {
+	return m.SigningIdentities
+}
+
+// a list of strings, stored in a single column as a json array
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (l *StringList) Scan(src any) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(l))
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(l))
+	default:
+		return fmt.Errorf("unsupported src type %T", src)
+	}
+}
@@ -75,11 +75,11 @@ func createVerifiableVersion(r primitives.VerifiableAndRecordable, at *primitive
 	r.StampCreatedAt(at)
 
 	if firstRecord {
-		if err := algorithms.CreatePrefix(r); err != nil {
+		if err := algorithms.CreatePrefix(r, nil, nil); err != nil {
 			return err
 		}
 	} else {
-		if err := algorithms.SelfAddress(r); err != nil {
+		if err := algorithms.SelfAddress(r, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -266,7 +266,7 @@ func exerciseFixedSignableRecorder() error {
 }
 
 func createFixedSignedVersion(s primitives.SignableAndRecordable, at primitives.Timestamp, key interfaces.SigningKey) error {
-	if err := algorithms.Sign(s, key, func() error {
+	if err := algorithms.Sign(s, key, nil, func() error {
 		return createFixedVerifiableVersion(s, at)
 	}); err != nil {
 		return err
@@ -291,7 +291,7 @@ func verifySignature(s primitives.SignableAndRecordable, key interfaces.SigningK
 	verificationKeyStore := examples.NewVerificationKeyStore()
 	verificationKeyStore.Add(identity, verificationKey)
 
-	if err := algorithms.VerifySignature(s, verificationKeyStore); err != nil {
+	if err := algorithms.VerifySignature(s, verificationKeyStore, nil); err != nil {
 		return err
 	}
 
@@ -15,6 +15,11 @@ type SignableAndRecordable interface {
 	Signable
 }
 
+type MultiSignableAndRecordable interface {
+	VerifiableAndRecordable
+	MultiSignable
+}
+
 type VerifiableRecorder struct {
 	Prefixer    // [id from SelfAddresser and] prefix
 	Sequencer   // sequenceNumber
@@ -27,3 +32,8 @@ type SignableRecorder struct {
 	VerifiableRecorder
 	Signer // signingIdentity, signature
 }
+
+type MultiSignableRecorder struct {
+	VerifiableRecorder
+	MultiSigner // signingIdentities, signatures
+}
@@ -28,6 +28,22 @@ func (t *Timestamper) StampCreatedAt(when *Timestamp) {
 	t.CreatedAt = &utc
 }
 
+func (t Timestamper) GetCreatedAt() *Timestamp {
+	return t.CreatedAt
+}
+
+// records that don't embed a Timestamper, or that weren't timestamped, have no creation time. this
+// isn't part of Timestampable, so implementations without GetCreatedAt keep compiling.
+func CreatedAt(record any) *time.Time {
+	getter, ok := record.(interface{ GetCreatedAt() *Timestamp })
+	if !ok || getter.GetCreatedAt() == nil {
+		return nil
+	}
+
+	when := time.Time(*getter.GetCreatedAt())
+	return &when
+}
+
 const ConsistentMilli = `2006-01-02T15:04:05.000Z07:00`
 
 func (t Timestamp) UTC() Timestamp {
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/clauses"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+// point-in-time reads only consider versions created at or before when, reconstructing what the
+// latest version of each chain was at that moment. they require a timestamping repository.
+
+func (r VerifiableRepository[T]) GetLatestByPrefixAsOf(
+	ctx context.Context,
+	record T,
+	prefix string,
+	when primitives.Timestamp,
+) error {
+	if err := r.getLatestRecordByPrefixAsOf(ctx, record, prefix, when); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r VerifiableRepository[T]) ListLatestByPrefixAsOf(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+	when primitives.Timestamp,
+) error {
+	if err := r.selectLatestByPrefixAsOf(ctx, records, preFilter, condition, order, limit, when); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifyRecord)
+}
+
+func (r SignableRepository[T]) GetLatestByPrefixAsOf(
+	ctx context.Context,
+	record T,
+	prefix string,
+	when primitives.Timestamp,
+) error {
+	if err := r.getLatestRecordByPrefixAsOf(ctx, record, prefix, when); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifySignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r SignableRepository[T]) ListLatestByPrefixAsOf(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+	when primitives.Timestamp,
+) error {
+	if err := r.selectLatestByPrefixAsOf(ctx, records, preFilter, condition, order, limit, when); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifySignedRecord)
+}
+
+func (r MultiSignableRepository[T]) GetLatestByPrefixAsOf(
+	ctx context.Context,
+	record T,
+	prefix string,
+	when primitives.Timestamp,
+) error {
+	if err := r.getLatestRecordByPrefixAsOf(ctx, record, prefix, when); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyMultiSignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) ListLatestByPrefixAsOf(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+	when primitives.Timestamp,
+) error {
+	if err := r.selectLatestByPrefixAsOf(ctx, records, preFilter, condition, order, limit, when); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifyMultiSignedRecord)
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) getLatestRecordByPrefixAsOf(
+	ctx context.Context,
+	record T,
+	prefix string,
+	when primitives.Timestamp,
+) error {
+	if !r.timestamp {
+		return fmt.Errorf("point-in-time reads require a timestamping repository")
+	}
+
+	condition := clauses.And([]data.ClauseOrExpression{
+		expressions.Equal("prefix", prefix),
+		expressions.LessThanOrEqual("created_at", when.UTC()),
+	})
+
+	return r.get(ctx, record, condition, orderings.Descending("sequence_number"))
+}
+
+// the time bound joins the pre-filter, so versions created later never take part in the ranking
+// and the condition applies to whichever version was latest at the time
+func (r VerifiableRepository[T]) selectLatestByPrefixAsOf(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+	when primitives.Timestamp,
+) error {
+	if !r.timestamp {
+		return fmt.Errorf("point-in-time reads require a timestamping repository")
+	}
+
+	if preFilter == nil {
+		return fmt.Errorf("for performance reasons, must supply a pre-filter")
+	}
+
+	preFilter = clauses.And([]data.ClauseOrExpression{
+		preFilter,
+		expressions.LessThanOrEqual("created_at", when.UTC()),
+	})
+
+	return r.selectLatestByPrefix(ctx, records, preFilter, condition, order, limit)
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+)
+
+// bounds the parameters bound by a single multi-row insert (sqlite's historical limit)
+const maxInsertParameters = 999
+
+// creates a version of each record, all or nothing. the same record passed again becomes the next
+// version after itself, but any other record sharing a prefix with an earlier one must follow the
+// version that one became, as CreateVersion would require. on failure every record is restored to
+// the state it was passed in with.
+func (r VerifiableRepository[T]) CreateVersions(ctx context.Context, records []T) error {
+	return r.createVersions(ctx, records, r.prepareVerifiableRecord)
+}
+
+func (r SignableRepository[T]) CreateVersions(ctx context.Context, records []T) error {
+	return r.createVersions(ctx, records, r.prepareSignedRecord)
+}
+
+func (r MultiSignableRepository[T]) CreateVersions(ctx context.Context, records []T) error {
+	return r.createVersions(ctx, records, r.prepareMultiSignedRecord)
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) createVersions(ctx context.Context, records []T, prepare func(T) error) error {
+	restores := []func(){}
+	restore := func() {
+		// the earliest snapshot of a record passed more than once must win
+		for _, restore := range slices.Backward(restores) {
+			restore()
+		}
+	}
+
+	prepared := []T{}
+	latest := map[string]T{}
+
+	for _, record := range records {
+		restores = append(restores, r.snapshot(record))
+
+		// a stale copy would take the position of a version earlier in the batch
+		if previous, exists := latest[record.GetPrefix()]; exists && record.GetId() != previous.GetId() {
+			restore()
+			return SequenceConflictError{
+				Prefix:         record.GetPrefix(),
+				SequenceNumber: record.GetSequenceNumber() + 1,
+				Err:            fmt.Errorf("the batch already reached version %d", previous.GetSequenceNumber()),
+			}
+		}
+
+		if err := prepare(record); err != nil {
+			restore()
+			return err
+		}
+
+		// the record may be prepared again later in the batch, so insert a copy
+		version := r.copyRecord(record)
+		prepared = append(prepared, version)
+		latest[version.GetPrefix()] = version
+	}
+
+	if !r.write {
+		return nil
+	}
+
+	if err := data.WithTransaction(ctx, r.store, func(ctx context.Context) error {
+		if err := r.checkCreatedAts(ctx, prepared); err != nil {
+			return err
+		}
+
+		return r.insertRecords(ctx, prepared)
+	}); err != nil {
+		restore()
+
+		// the insert has been rolled back, so the conflict can be inspected on ctx
+		if r.store.IsUniqueViolation(err) || r.store.IsPrimaryKeyViolation(err) {
+			return r.batchConflict(ctx, prepared, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// a multi-row insert doesn't say which row conflicted, so each record is checked against the
+// version stored at its position
+func (r VerifiableRepository[T]) batchConflict(ctx context.Context, records []T, err error) error {
+	for _, record := range records {
+		existing := r.newRecord()
+		if r.getRecordBySequenceNumber(ctx, existing, record.GetPrefix(), uint(record.GetSequenceNumber())) != nil {
+			continue
+		}
+
+		if existing.GetId() == record.GetId() {
+			return DuplicateRecordError{Id: record.GetId(), Err: err}
+		}
+
+		return SequenceConflictError{
+			Prefix:         record.GetPrefix(),
+			SequenceNumber: record.GetSequenceNumber(),
+			Err:            err,
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrSequenceConflict, err)
+}
+
+// a record following another in the batch is checked against it, the rest against the store
+func (r VerifiableRepository[T]) checkCreatedAts(ctx context.Context, records []T) error {
+	byId := map[string]T{}
+	for _, record := range records {
+		byId[record.GetId()] = record
+
+		if previous := record.GetPrevious(); previous != nil {
+			if predecessor, exists := byId[*previous]; exists {
+				if err := checkCreatedAfter(record, predecessor); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		if err := r.checkCreatedAt(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r VerifiableRepository[T]) copyRecord(record T) T {
+	version := r.newRecord()
+	reflect.ValueOf(version).Elem().Set(reflect.ValueOf(record).Elem())
+	return version
+}
+
+// records are grouped by the columns they populate, then written in chunks
+func (r VerifiableRepository[T]) insertRecords(ctx context.Context, records []T) error {
+	columns := [][]string{}
+	groups := [][]T{}
+
+	for _, record := range records {
+		fieldNames := r.getFieldNames(record)
+
+		i := slices.IndexFunc(columns, func(names []string) bool {
+			return slices.Equal(names, fieldNames)
+		})
+
+		if i < 0 {
+			columns = append(columns, fieldNames)
+			groups = append(groups, []T{})
+			i = len(groups) - 1
+		}
+
+		groups[i] = append(groups[i], record)
+	}
+
+	for i, group := range groups {
+		query := r.store.Dialect().Insert((*new(T)).TableName(), columns[i])
+		chunkSize := max(1, maxInsertParameters/len(columns[i]))
+
+		for chunk := range slices.Chunk(group, chunkSize) {
+			if _, err := r.store.Sql(ctx).NamedExecContext(ctx, query, chunk); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+const benchmarkRecords = 1000
+
+func BenchmarkSelectVerifiable(b *testing.B) {
+	ctx := context.Background()
+
+	r, err := createVerifiableRepository()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	records := []*VerifiableModel{}
+	for i := range benchmarkRecords {
+		records = append(records, &VerifiableModel{Foo: "benchmark", Bar: fmt.Sprintf("%d", i)})
+	}
+
+	if err := r.CreateVersions(ctx, records); err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkSelect(b, r, r.(*repository.VerifiableRepository[*VerifiableModel]).SetVerificationWorkers)
+}
+
+func BenchmarkSelectSignable(b *testing.B) {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	records := []*SignableModel{}
+	for i := range benchmarkRecords {
+		records = append(records, &SignableModel{Foo: "benchmark", Bar: fmt.Sprintf("%d", i)})
+	}
+
+	if err := r.CreateVersions(ctx, records); err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkSelect(b, r, r.(*repository.SignableRepository[*SignableModel]).SetVerificationWorkers)
+}
+
+// speedups are bounded by GOMAXPROCS
+func benchmarkSelect[T primitives.VerifiableAndRecordable](b *testing.B, r repository.Repository[T], setWorkers func(int)) {
+	ctx := context.Background()
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			setWorkers(workers)
+
+			for b.Loop() {
+				records := []T{}
+				if err := r.Select(ctx, &records, expressions.Equal("foo", "benchmark"), nil, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// what VerifyChain found. PrefixMismatches names records that belong to the chain without carrying
+// its prefix: a first record whose id isn't the prefix, or a record under another prefix that follows
+// one of the chain's records (or is its first record).
+type ChainReport struct {
+	Prefix                   string          `json:"prefix"`
+	Length                   int             `json:"length"`
+	InvalidRecords           []InvalidRecord `json:"invalidRecords,omitempty"`
+	BrokenLinks              []BrokenLink    `json:"brokenLinks,omitempty"`
+	Gaps                     []SequenceGap   `json:"gaps,omitempty"`
+	DuplicateSequenceNumbers []uint64        `json:"duplicateSequenceNumbers,omitempty"`
+	PrefixMismatches         []string        `json:"prefixMismatches,omitempty"`
+	Backdated                []string        `json:"backdated,omitempty"`
+}
+
+// a record whose self-address, prefix or signature failed verification
+type InvalidRecord struct {
+	Id             string `json:"id"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	Reason         string `json:"reason"`
+}
+
+// a record whose previous does not point at the record preceding it in the chain
+type BrokenLink struct {
+	Id             string  `json:"id"`
+	SequenceNumber uint64  `json:"sequenceNumber"`
+	Previous       *string `json:"previous,omitempty"`
+}
+
+// an inclusive range of sequence numbers missing from the chain
+type SequenceGap struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+func (c ChainReport) Intact() bool {
+	return len(c.InvalidRecords) == 0 &&
+		len(c.BrokenLinks) == 0 &&
+		len(c.Gaps) == 0 &&
+		len(c.DuplicateSequenceNumbers) == 0 &&
+		len(c.PrefixMismatches) == 0 &&
+		len(c.Backdated) == 0
+}
+
+func (r VerifiableRepository[T]) VerifyChain(ctx context.Context, prefix string) (*ChainReport, error) {
+	return r.verifyChain(ctx, prefix, r.verifyRecord)
+}
+
+func (r SignableRepository[T]) VerifyChain(ctx context.Context, prefix string) (*ChainReport, error) {
+	return r.verifyChain(ctx, prefix, r.verifySignedRecord)
+}
+
+func (r MultiSignableRepository[T]) VerifyChain(ctx context.Context, prefix string) (*ChainReport, error) {
+	return r.verifyChain(ctx, prefix, r.verifyMultiSignedRecord)
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) verifyChain(ctx context.Context, prefix string, verify func(context.Context, T) error) (*ChainReport, error) {
+	records := []T{}
+	if err := r.listRecordsByPrefix(ctx, &records, prefix); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, ErrNotFound
+	}
+
+	report := &ChainReport{
+		Prefix: prefix,
+		Length: len(records),
+	}
+
+	// records arrive ordered by sequence number, so duplicates are adjacent
+	bySequenceNumber := [][]T{}
+	scoped := withVerificationScope(ctx)
+	for _, record := range records {
+		if err := verify(scoped, record); err != nil {
+			report.InvalidRecords = append(report.InvalidRecords, InvalidRecord{
+				Id:             record.GetId(),
+				SequenceNumber: record.GetSequenceNumber(),
+				Reason:         err.Error(),
+			})
+		}
+
+		if record.GetSequenceNumber() == 0 && !strings.EqualFold(record.GetId(), prefix) {
+			report.PrefixMismatches = append(report.PrefixMismatches, record.GetId())
+		}
+
+		last := len(bySequenceNumber) - 1
+		if last >= 0 && bySequenceNumber[last][0].GetSequenceNumber() == record.GetSequenceNumber() {
+			bySequenceNumber[last] = append(bySequenceNumber[last], record)
+		} else {
+			bySequenceNumber = append(bySequenceNumber, []T{record})
+		}
+	}
+
+	moved, err := r.movedRecords(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range moved {
+		report.PrefixMismatches = append(report.PrefixMismatches, record.GetId())
+	}
+
+	expected := uint64(0)
+	var predecessors []T
+	for _, group := range bySequenceNumber {
+		sequenceNumber := group[0].GetSequenceNumber()
+
+		if len(group) > 1 {
+			report.DuplicateSequenceNumbers = append(report.DuplicateSequenceNumbers, sequenceNumber)
+		}
+
+		if sequenceNumber > expected {
+			report.Gaps = append(report.Gaps, SequenceGap{
+				From: expected,
+				To:   sequenceNumber - 1,
+			})
+
+			// without the preceding record there is nothing to check links against
+			predecessors = nil
+		}
+
+		for _, record := range group {
+			if !r.linked(record, predecessors) {
+				report.BrokenLinks = append(report.BrokenLinks, BrokenLink{
+					Id:             record.GetId(),
+					SequenceNumber: sequenceNumber,
+					Previous:       record.GetPrevious(),
+				})
+			}
+
+			if r.backdated(record, predecessors) {
+				report.Backdated = append(report.Backdated, record.GetId())
+			}
+		}
+
+		predecessors = group
+		expected = sequenceNumber + 1
+	}
+
+	return report, nil
+}
+
+// records that belong to the chain, being its first record or following one of its records (or one
+// of these), but that carry another prefix. a record moved out of the chain leaves only a gap in it.
+func (r VerifiableRepository[T]) movedRecords(ctx context.Context, prefix string) ([]T, error) {
+	table := r.tableName()
+	query := fmt.Sprintf(
+		"WITH RECURSIVE moved AS ("+
+			"SELECT * FROM %s WHERE prefix <> ? AND (id = ? OR previous IN (SELECT id FROM %s WHERE prefix = ?)) "+
+			"UNION "+
+			"SELECT following.* FROM %s following JOIN moved ON following.previous = moved.id WHERE following.prefix <> ?"+
+			") SELECT * FROM moved ORDER BY sequence_number ASC",
+		table,
+		table,
+		table,
+	)
+
+	records := []T{}
+	if err := r.store.Sql(ctx).SelectContext(ctx, &records, r.store.Dialect().ReplacePlaceholders(query), prefix, prefix, prefix, prefix); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (r VerifiableRepository[T]) linked(record T, predecessors []T) bool {
+	previous := record.GetPrevious()
+
+	if record.GetSequenceNumber() == 0 {
+		return previous == nil
+	}
+
+	if predecessors == nil {
+		return true
+	}
+
+	if previous == nil {
+		return false
+	}
+
+	for _, predecessor := range predecessors {
+		if strings.EqualFold(*previous, predecessor.GetId()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// created before the version it follows. a signing key's validity is judged by created_at, so a
+// backdated version may have been signed after its key stopped being valid.
+func (r VerifiableRepository[T]) backdated(record T, predecessors []T) bool {
+	previous := record.GetPrevious()
+	if previous == nil {
+		return false
+	}
+
+	for _, predecessor := range predecessors {
+		if strings.EqualFold(*previous, predecessor.GetId()) && createdBefore(record, predecessor) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+)
+
+// counting never reads records, so nothing is verified. use the select or iterator variants when
+// the records themselves must be trusted.
+
+// a nil condition counts every record in the table
+func (r VerifiableRepository[T]) Count(ctx context.Context, condition data.ClauseOrExpression) (uint, error) {
+	if condition == nil {
+		condition = expressions.NotNull("id")
+	}
+
+	query, values := r.countQuery(condition)
+	return r.count(ctx, query, values)
+}
+
+func (r VerifiableRepository[T]) CountLatestByPrefix(
+	ctx context.Context,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+) (uint, error) {
+	query, values, err := r.latestByPrefixQuery(preFilter, condition)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.count(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS counted", query), values)
+}
+
+func (r VerifiableRepository[T]) Exists(ctx context.Context, condition data.ClauseOrExpression) (bool, error) {
+	if condition == nil {
+		condition = expressions.NotNull("id")
+	}
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s)", r.tableName(), condition.String())
+	query = r.store.Dialect().ReplacePlaceholders(query)
+
+	var exists bool
+	if err := r.store.Sql(ctx).GetContext(ctx, &exists, query, condition.Values()...); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) countQuery(condition data.ClauseOrExpression) (string, []any) {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", r.tableName(), condition.String()), condition.Values()
+}
+
+func (r VerifiableRepository[T]) count(ctx context.Context, query string, values []any) (uint, error) {
+	query = r.store.Dialect().ReplacePlaceholders(query)
+
+	var count uint
+	if err := r.store.Sql(ctx).GetContext(ctx, &count, query, values...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
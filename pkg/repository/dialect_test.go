@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// records queries instead of running them, so sql for other dialects can be checked without a server
+type RecordingStore struct {
+	dialect data.Dialect
+	queries []string
+}
+
+func (s *RecordingStore) Sql(ctx context.Context) data.SQLStore {
+	return s
+}
+
+func (*RecordingStore) BeginTransaction(ctx context.Context, opts *sql.TxOptions) (context.Context, error) {
+	return ctx, nil
+}
+
+func (*RecordingStore) CommitTransaction(ctx context.Context) error {
+	return nil
+}
+
+func (*RecordingStore) RollbackTransaction(ctx context.Context) error {
+	return nil
+}
+
+func (s *RecordingStore) Dialect() data.Dialect {
+	return s.dialect
+}
+
+func (*RecordingStore) IsUniqueViolation(err error) bool {
+	return false
+}
+
+func (*RecordingStore) IsPrimaryKeyViolation(err error) bool {
+	return false
+}
+
+func (s *RecordingStore) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	s.record(query)
+	return nil, nil
+}
+
+func (s *RecordingStore) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	s.record(query)
+	return sql.ErrNoRows
+}
+
+func (s *RecordingStore) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	s.record(query)
+	return nil, nil
+}
+
+func (s *RecordingStore) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	s.record(query)
+	return nil
+}
+
+func (s *RecordingStore) QueryRowsContext(ctx context.Context, query string, args ...any) (data.Rows, error) {
+	s.record(query)
+	return nil, sql.ErrNoRows
+}
+
+func (s *RecordingStore) record(query string) {
+	s.queries = append(s.queries, strings.Join(strings.Fields(query), " "))
+}
+
+func TestPostgresQueries(t *testing.T) {
+	if err := testPostgresQueries(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testPostgresQueries() error {
+	ctx := context.Background()
+	store := &RecordingStore{dialect: dialects.Postgres()}
+
+	r := repository.NewVerifiableRepository[*DeterministicModel](store, true, false, nil)
+
+	record := &DeterministicModel{Foo: "bar", Bar: "baz"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	_ = r.GetLatestByPrefix(ctx, &DeterministicModel{}, record.GetPrefix())
+
+	limit := uint(5)
+	records := []*DeterministicModel{}
+	if err := r.Select(ctx, &records, expressions.Any("id", []any{"a", "b"}, dialects.Postgres().AnyBuilder()), orderings.Ascending("id"), &limit); err != nil {
+		return err
+	}
+
+	if err := r.ListLatestByPrefix(ctx, &records, expressions.Equal("foo", "bar"), expressions.Equal("bar", "baz"), nil, nil); err != nil {
+		return err
+	}
+
+	_, _ = r.CountLatestByPrefix(ctx, expressions.Equal("foo", "bar"), expressions.Equal("bar", "baz"))
+	_, _ = r.Exists(ctx, expressions.Equal("prefix", record.GetPrefix()))
+
+	expected := []string{
+		`INSERT INTO "deterministic" ("id", "prefix", "sequence_number", "previous", "foo", "bar") VALUES (:id, :prefix, :sequence_number, :previous, :foo, :bar)`,
+		`SELECT * FROM "deterministic" WHERE prefix=$1 ORDER BY sequence_number DESC LIMIT 1`,
+		`SELECT * FROM "deterministic" WHERE id=ANY($1) ORDER BY id ASC LIMIT 5`,
+		`WITH latest AS (SELECT DISTINCT ON (prefix) * FROM "deterministic" WHERE foo=$1 ORDER BY prefix, sequence_number DESC) SELECT * FROM latest WHERE bar=$2`,
+		`SELECT COUNT(*) FROM (WITH latest AS (SELECT DISTINCT ON (prefix) * FROM "deterministic" WHERE foo=$1 ORDER BY prefix, sequence_number DESC) SELECT * FROM latest WHERE bar=$2) AS counted`,
+		`SELECT EXISTS (SELECT 1 FROM "deterministic" WHERE prefix=$1)`,
+	}
+
+	if len(store.queries) != len(expected) {
+		return fmt.Errorf("unexpected query count: %d", len(store.queries))
+	}
+
+	for i, query := range store.queries {
+		if query != expected[i] {
+			return fmt.Errorf("unexpected query %d: %s", i, query)
+		}
+	}
+
+	return nil
+}
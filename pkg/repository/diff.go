@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+type VersionDiff struct {
+	Prefix             string        `json:"prefix"`
+	FromId             string        `json:"fromId"`
+	FromSequenceNumber uint64        `json:"fromSequenceNumber"`
+	ToId               string        `json:"toId"`
+	ToSequenceNumber   uint64        `json:"toSequenceNumber"`
+	Changes            []FieldChange `json:"changes"`
+}
+
+// a field whose value differs between two versions. nil values are absent in that version.
+type FieldChange struct {
+	Column string `json:"column"`
+	JSON   string `json:"json"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// loads and verifies both versions of the chain, then compares them with DiffRecords
+func (r VerifiableRepository[T]) Diff(
+	ctx context.Context,
+	prefix string,
+	fromSequenceNumber uint,
+	toSequenceNumber uint,
+	includeBookkeeping bool,
+) (*VersionDiff, error) {
+	return r.diff(ctx, prefix, fromSequenceNumber, toSequenceNumber, includeBookkeeping, r.GetBySequenceNumber)
+}
+
+func (r SignableRepository[T]) Diff(
+	ctx context.Context,
+	prefix string,
+	fromSequenceNumber uint,
+	toSequenceNumber uint,
+	includeBookkeeping bool,
+) (*VersionDiff, error) {
+	return r.diff(ctx, prefix, fromSequenceNumber, toSequenceNumber, includeBookkeeping, r.GetBySequenceNumber)
+}
+
+func (r MultiSignableRepository[T]) Diff(
+	ctx context.Context,
+	prefix string,
+	fromSequenceNumber uint,
+	toSequenceNumber uint,
+	includeBookkeeping bool,
+) (*VersionDiff, error) {
+	return r.diff(ctx, prefix, fromSequenceNumber, toSequenceNumber, includeBookkeeping, r.GetBySequenceNumber)
+}
+
+// reports the fields that differ between two records, in declaration order. the fields of the
+// embedded recorders (id, prefix, sequence number and so on) always differ between versions, so
+// they are only compared when includeBookkeeping is set.
+func DiffRecords[T primitives.VerifiableAndRecordable](from, to T, includeBookkeeping bool) []FieldChange {
+	before := fieldValues(from)
+	after := fieldValues(to)
+
+	changes := []FieldChange{}
+	for _, field := range schema.LeafFields(reflect.TypeFor[T](), reflect.Value{}) {
+		if field.Bookkeeping && !includeBookkeeping {
+			continue
+		}
+
+		if reflect.DeepEqual(before[field.Column], after[field.Column]) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Column: field.Column,
+			JSON:   field.JSON,
+			Before: before[field.Column],
+			After:  after[field.Column],
+		})
+	}
+
+	return changes
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) diff(
+	ctx context.Context,
+	prefix string,
+	fromSequenceNumber uint,
+	toSequenceNumber uint,
+	includeBookkeeping bool,
+	getBySequenceNumber func(context.Context, T, string, uint) error,
+) (*VersionDiff, error) {
+	from := r.newRecord()
+	if err := getBySequenceNumber(ctx, from, prefix, fromSequenceNumber); err != nil {
+		return nil, err
+	}
+
+	to := r.newRecord()
+	if err := getBySequenceNumber(ctx, to, prefix, toSequenceNumber); err != nil {
+		return nil, err
+	}
+
+	return &VersionDiff{
+		Prefix:             prefix,
+		FromId:             from.GetId(),
+		FromSequenceNumber: from.GetSequenceNumber(),
+		ToId:               to.GetId(),
+		ToSequenceNumber:   to.GetSequenceNumber(),
+		Changes:            DiffRecords(from, to, includeBookkeeping),
+	}, nil
+}
+
+// pointers are dereferenced so values compare and serialise as what they point at
+func fieldValues[T primitives.VerifiableAndRecordable](record T) map[string]any {
+	values := map[string]any{}
+	for _, field := range schema.LeafFields(reflect.TypeOf(record), reflect.ValueOf(record)) {
+		value := field.Value
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+
+			value = value.Elem()
+		}
+
+		values[field.Column] = value.Interface()
+	}
+
+	return values
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+var (
+	ErrNotFound         = errors.New("record not found")
+	ErrSequenceConflict = errors.New("sequence conflict")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrDuplicateRecord  = errors.New("duplicate record")
+	ErrNotController    = errors.New("signer does not control the chain")
+	ErrBackdated        = errors.New("version was created before the version it follows")
+)
+
+// the record being inserted has the id of one already stored: it is the same record, written twice
+type DuplicateRecordError struct {
+	Id  string
+	Err error
+}
+
+func (e DuplicateRecordError) Error() string {
+	return fmt.Sprintf("%s %s: %s", ErrDuplicateRecord, e.Id, e.Err)
+}
+
+func (e DuplicateRecordError) Is(target error) bool {
+	return target == ErrDuplicateRecord
+}
+
+func (e DuplicateRecordError) Unwrap() error {
+	return e.Err
+}
+
+// the version was signed under a different identity than the chain's first version. only checked
+// with key stores whose identities outlive their keys (interfaces.HistoricalVerificationKeyStore).
+type ControllerError struct {
+	Prefix          string
+	SequenceNumber  uint64
+	Controller      string
+	SigningIdentity string
+}
+
+func (e ControllerError) Error() string {
+	return fmt.Sprintf("%s at %s/%d: signed by %s, not %s", ErrNotController, e.Prefix, e.SequenceNumber, e.SigningIdentity, e.Controller)
+}
+
+func (e ControllerError) Is(target error) bool {
+	return target == ErrNotController
+}
+
+type SequenceConflictError struct {
+	Prefix         string
+	SequenceNumber uint64
+	Err            error
+}
+
+func (e SequenceConflictError) Error() string {
+	return fmt.Sprintf("%s at %s/%d: %s", ErrSequenceConflict, e.Prefix, e.SequenceNumber, e.Err)
+}
+
+func (e SequenceConflictError) Is(target error) bool {
+	return target == ErrSequenceConflict
+}
+
+func (e SequenceConflictError) Unwrap() error {
+	return e.Err
+}
+
+// returned by CreateVersion when it loses a race, with the version that won
+type VersionConflictError[T primitives.VerifiableAndRecordable] struct {
+	SequenceConflictError
+	Latest T
+}
+
+// so errors.As finds the embedded SequenceConflictError as well as the driver error it carries
+func (e VersionConflictError[T]) Unwrap() []error {
+	return []error{e.SequenceConflictError, e.Err}
+}
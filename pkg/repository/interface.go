@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"iter"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
@@ -13,6 +14,17 @@ type Repository[T primitives.VerifiableAndRecordable] interface {
 		record T,
 	) error
 
+	CreateVersions(
+		ctx context.Context,
+		records []T,
+	) error
+
+	UpdateWithRetry(
+		ctx context.Context,
+		prefix string,
+		mutate func(T) error,
+	) (T, error)
+
 	GetById(
 		ctx context.Context,
 		record T,
@@ -61,4 +73,89 @@ type Repository[T primitives.VerifiableAndRecordable] interface {
 		order data.Ordering,
 		limit *uint,
 	) error
+
+	GetLatestByPrefixAsOf(
+		ctx context.Context,
+		record T,
+		prefix string,
+		when primitives.Timestamp,
+	) error
+
+	ListLatestByPrefixAsOf(
+		ctx context.Context,
+		records *[]T,
+		preFilter data.ClauseOrExpression,
+		condition data.ClauseOrExpression,
+		order data.Ordering,
+		limit *uint,
+		when primitives.Timestamp,
+	) error
+
+	SelectIter(
+		ctx context.Context,
+		condition data.ClauseOrExpression,
+		order data.Ordering,
+		limit *uint,
+	) iter.Seq2[T, error]
+
+	ListByPrefixIter(
+		ctx context.Context,
+		prefix string,
+	) iter.Seq2[T, error]
+
+	ListLatestByPrefixIter(
+		ctx context.Context,
+		preFilter data.ClauseOrExpression,
+		condition data.ClauseOrExpression,
+		order data.Ordering,
+		limit *uint,
+	) iter.Seq2[T, error]
+
+	SelectPage(
+		ctx context.Context,
+		records *[]T,
+		condition data.ClauseOrExpression,
+		order data.Ordering,
+		limit uint,
+		after string,
+	) (string, error)
+
+	ListLatestByPrefixPage(
+		ctx context.Context,
+		records *[]T,
+		preFilter data.ClauseOrExpression,
+		condition data.ClauseOrExpression,
+		order data.Ordering,
+		limit uint,
+		after string,
+	) (string, error)
+
+	Count(
+		ctx context.Context,
+		condition data.ClauseOrExpression,
+	) (uint, error)
+
+	CountLatestByPrefix(
+		ctx context.Context,
+		preFilter data.ClauseOrExpression,
+		condition data.ClauseOrExpression,
+	) (uint, error)
+
+	Exists(
+		ctx context.Context,
+		condition data.ClauseOrExpression,
+	) (bool, error)
+
+	Diff(
+		ctx context.Context,
+		prefix string,
+		fromSequenceNumber uint,
+		toSequenceNumber uint,
+		includeBookkeeping bool,
+	) (*VersionDiff, error)
+
+	VerifyChain(
+		ctx context.Context,
+		prefix string,
+	) (*ChainReport, error)
 }
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+// records are signed by every key in signingKeys, which must together meet the threshold, and are
+// read back only if the signatures they carry meet it
+type MultiSignableRepository[T primitives.MultiSignableAndRecordable] struct {
+	VerifiableRepository[T]
+
+	threshold            algorithms.Threshold
+	signingKeys          []interfaces.SigningKey
+	verificationKeyStore interfaces.VerificationKeyStore
+}
+
+// pass a nil noncer to omit nonces. signingKeys may be empty for a read only repository.
+func NewMultiSignableRepository[T primitives.MultiSignableAndRecordable](
+	store data.Store,
+	write bool,
+	timestamp bool,
+	noncer interfaces.Noncer,
+	threshold algorithms.Threshold,
+	signingKeys []interfaces.SigningKey,
+	verificationKeyStore interfaces.VerificationKeyStore,
+) *MultiSignableRepository[T] {
+	return &MultiSignableRepository[T]{
+		VerifiableRepository: VerifiableRepository[T]{
+			store:  store,
+			noncer: noncer,
+
+			write:     write,
+			timestamp: timestamp,
+
+			cursorKey: newCursorKey(),
+		},
+
+		threshold:            threshold,
+		signingKeys:          signingKeys,
+		verificationKeyStore: verificationKeyStore,
+	}
+}
+
+func (r MultiSignableRepository[T]) CreateVersion(ctx context.Context, record T) error {
+	return r.createVersion(ctx, record, r.prepareMultiSignedRecord, r.GetLatestByPrefix)
+}
+
+func (r MultiSignableRepository[T]) UpdateWithRetry(ctx context.Context, prefix string, mutate func(T) error) (T, error) {
+	return r.updateWithRetry(ctx, prefix, mutate, r.CreateVersion, r.GetLatestByPrefix)
+}
+
+func (r MultiSignableRepository[T]) GetById(ctx context.Context, record T, id string) error {
+	if err := r.getRecordById(ctx, record, id); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyMultiSignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) GetBySequenceNumber(ctx context.Context, record T, prefix string, sequenceNumber uint) error {
+	if err := r.getRecordBySequenceNumber(ctx, record, prefix, sequenceNumber); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyMultiSignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) GetLatestByPrefix(ctx context.Context, record T, prefix string) error {
+	if err := r.getLatestRecordByPrefix(ctx, record, prefix); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyMultiSignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) ListByPrefix(ctx context.Context, records *[]T, prefix string) error {
+	if err := r.listRecordsByPrefix(ctx, records, prefix); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifyMultiSignedRecord)
+}
+
+func (r MultiSignableRepository[T]) Get(
+	ctx context.Context,
+	record T,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+) error {
+	if err := r.get(ctx, record, condition, order); err != nil {
+		return err
+	}
+
+	if err := r.verify(ctx, record, r.verifyMultiSignedRecord); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) Select(
+	ctx context.Context,
+	records *[]T,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) error {
+	if err := r._select(ctx, records, condition, order, limit); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifyMultiSignedRecord)
+}
+
+func (r MultiSignableRepository[T]) ListLatestByPrefix(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) error {
+	if err := r.selectLatestByPrefix(ctx, records, preFilter, condition, order, limit); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records, r.verifyMultiSignedRecord)
+}
+
+// helpers
+
+func (r MultiSignableRepository[T]) prepareMultiSignedRecord(record T) error {
+	signers := []string{}
+	for _, key := range r.signingKeys {
+		identity, err := key.Identity()
+		if err != nil {
+			return err
+		}
+
+		signers = append(signers, identity)
+	}
+
+	// refuse to write a record that could never be read back
+	if !r.threshold.Satisfied(signers) {
+		return algorithms.ThresholdError{Signers: signers}
+	}
+
+	if err := algorithms.MultiSign(record, r.threshold.Identities(), r.signingKeys, r.canonicalizer, func() error {
+		return r.prepareVerifiableRecord(record)
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r MultiSignableRepository[T]) verifyMultiSignedRecord(ctx context.Context, record T) error {
+	if err := algorithms.VerifySignaturesContext(ctx, record, r.threshold, r.verificationKeyStore, r.canonicalizer); err != nil {
+		return err
+	}
+
+	if err := r.verifyRecord(ctx, record); err != nil {
+		return err
+	}
+
+	return nil
+}
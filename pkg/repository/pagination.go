@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/clauses"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+// the position after the last record of a page, bound to the table, ordering and filters it came from
+type cursor struct {
+	Table     string          `json:"t"`
+	Column    string          `json:"c"`
+	Direction string          `json:"d"`
+	Query     string          `json:"q"`
+	Value     json.RawMessage `json:"v"`
+	Id        string          `json:"i"`
+}
+
+// cursors are authenticated with this key. by default each repository generates its own, so set a
+// shared key if cursors must survive restarts or be honoured by other instances.
+func (r *VerifiableRepository[T]) SetCursorKey(key []byte) {
+	r.cursorKey = key
+}
+
+// pass an empty cursor for the first page. the returned cursor is empty once there are no more pages.
+// under VerifyCollect and VerifySkipInvalid the cursor is returned alongside a *VerificationReport.
+func (r VerifiableRepository[T]) SelectPage(
+	ctx context.Context,
+	records *[]T,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	return r.selectPage(ctx, records, nil, condition, order, limit, after, r.verifyRecord)
+}
+
+func (r VerifiableRepository[T]) ListLatestByPrefixPage(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	if preFilter == nil {
+		return "", fmt.Errorf("for performance reasons, must supply a pre-filter")
+	}
+
+	return r.selectPage(ctx, records, preFilter, condition, order, limit, after, r.verifyRecord)
+}
+
+func (r SignableRepository[T]) SelectPage(
+	ctx context.Context,
+	records *[]T,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	return r.selectPage(ctx, records, nil, condition, order, limit, after, r.verifySignedRecord)
+}
+
+func (r SignableRepository[T]) ListLatestByPrefixPage(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	if preFilter == nil {
+		return "", fmt.Errorf("for performance reasons, must supply a pre-filter")
+	}
+
+	return r.selectPage(ctx, records, preFilter, condition, order, limit, after, r.verifySignedRecord)
+}
+
+func (r MultiSignableRepository[T]) SelectPage(
+	ctx context.Context,
+	records *[]T,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	return r.selectPage(ctx, records, nil, condition, order, limit, after, r.verifyMultiSignedRecord)
+}
+
+func (r MultiSignableRepository[T]) ListLatestByPrefixPage(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+) (string, error) {
+	if preFilter == nil {
+		return "", fmt.Errorf("for performance reasons, must supply a pre-filter")
+	}
+
+	return r.selectPage(ctx, records, preFilter, condition, order, limit, after, r.verifyMultiSignedRecord)
+}
+
+// helpers
+
+// a nil preFilter selects from the whole table rather than the latest record of each prefix
+func (r VerifiableRepository[T]) selectPage(
+	ctx context.Context,
+	records *[]T,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit uint,
+	after string,
+	verify func(context.Context, T) error,
+) (string, error) {
+	if order == nil {
+		order = orderings.Ascending("id")
+	}
+
+	columnOrder, ok := order.(data.ColumnOrdering)
+	if !ok {
+		return "", fmt.Errorf("cannot page on %s: not an ordering on a single column", order)
+	}
+
+	field, err := r.field(columnOrder.Column())
+	if err != nil {
+		return "", err
+	}
+
+	// a NULL sorts outside the keyset condition, so it could neither end a page nor be paged past
+	if field.Nullable() {
+		return "", fmt.Errorf("cannot page on %s: column is nullable", field.Column)
+	}
+
+	keysetOrder := r.keysetOrder(columnOrder)
+
+	query, err := queryDigest(preFilter, condition)
+	if err != nil {
+		return "", err
+	}
+
+	if after != "" {
+		position, err := r.decodeCursor(after, columnOrder, query)
+		if err != nil {
+			return "", err
+		}
+
+		keyset, err := r.keysetCondition(position, field, columnOrder)
+		if err != nil {
+			return "", err
+		}
+
+		if condition == nil {
+			condition = keyset
+		} else {
+			condition = clauses.And([]data.ClauseOrExpression{condition, keyset})
+		}
+	}
+
+	if preFilter == nil {
+		if condition == nil {
+			condition = expressions.NotNull("id")
+		}
+
+		err = r._select(ctx, records, condition, keysetOrder, &limit)
+	} else {
+		err = r.selectLatestByPrefix(ctx, records, preFilter, condition, keysetOrder, &limit)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// the cursor is taken before verification, since skipping invalid records may shorten the page
+	next := ""
+	if uint(len(*records)) == limit && limit > 0 {
+		next, err = r.encodeCursor((*records)[len(*records)-1], columnOrder, query)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := r.verifyRecords(ctx, records, verify); err != nil {
+		if isVerificationReport(err) {
+			return next, err
+		}
+
+		return "", err
+	}
+
+	return next, nil
+}
+
+// id breaks ties so every record has a unique position, even when timestamps share a millisecond
+func (r VerifiableRepository[T]) keysetOrder(order data.ColumnOrdering) data.Ordering {
+	if order.Column() == "id" {
+		return order
+	}
+
+	if order.Direction() == "DESC" {
+		return orderings.Composite(order, orderings.Descending("id"))
+	}
+
+	return orderings.Composite(order, orderings.Ascending("id"))
+}
+
+// (column > value) OR (column = value AND id > id), with < for descending orders
+func (r VerifiableRepository[T]) keysetCondition(
+	position *cursor,
+	field *schema.Field,
+	order data.ColumnOrdering,
+) (data.ClauseOrExpression, error) {
+	value := reflect.New(field.Type)
+	if err := json.Unmarshal(position.Value, value.Interface()); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	beyond := func(column string, value any) data.ClauseOrExpression {
+		if order.Direction() == "DESC" {
+			return expressions.LessThan(column, value)
+		}
+
+		return expressions.GreaterThan(column, value)
+	}
+
+	if field.Column == "id" {
+		return beyond("id", position.Id), nil
+	}
+
+	return clauses.Or([]data.ClauseOrExpression{
+		beyond(field.Column, value.Elem().Interface()),
+		clauses.And([]data.ClauseOrExpression{
+			expressions.Equal(field.Column, value.Elem().Interface()),
+			beyond("id", position.Id),
+		}),
+	}), nil
+}
+
+func (r VerifiableRepository[T]) field(column string) (*schema.Field, error) {
+	for _, field := range schema.LeafFields(reflect.TypeFor[T](), reflect.Value{}) {
+		if field.Column == column {
+			return &field, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot page on %s: not a column of %s", column, (*new(T)).TableName())
+}
+
+func (r VerifiableRepository[T]) encodeCursor(record T, order data.ColumnOrdering, query string) (string, error) {
+	var value any
+	found := false
+
+	for _, field := range schema.LeafFields(reflect.TypeOf(record), reflect.ValueOf(record)) {
+		if field.Column == order.Column() {
+			value = field.Value.Interface()
+			found = true
+			break
+		}
+	}
+
+	if !found || value == nil || reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+		return "", fmt.Errorf("cannot page on %s: no value in record %s", order.Column(), record.GetId())
+	}
+
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(cursor{
+		Table:     (*new(T)).TableName(),
+		Column:    order.Column(),
+		Direction: order.Direction(),
+		Query:     query,
+		Value:     encodedValue,
+		Id:        record.GetId(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(r.cursorMac(payload)), nil
+}
+
+func (r VerifiableRepository[T]) decodeCursor(encoded string, order data.ColumnOrdering, query string) (*cursor, error) {
+	encodedPayload, encodedMac, found := strings.Cut(encoded, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMac)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if !hmac.Equal(mac, r.cursorMac(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	position := &cursor{}
+	if err := json.Unmarshal(payload, position); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// a cursor is only meaningful for the query that produced it
+	if position.Table != (*new(T)).TableName() ||
+		position.Column != order.Column() ||
+		position.Direction != order.Direction() ||
+		position.Query != query {
+		return nil, ErrInvalidCursor
+	}
+
+	return position, nil
+}
+
+// a digest of the rendered filters and their values, so a cursor can't carry a position into a
+// differently filtered query
+func queryDigest(preFilter, condition data.ClauseOrExpression) (string, error) {
+	rendered := []any{}
+	for _, filter := range []data.ClauseOrExpression{preFilter, condition} {
+		if filter == nil {
+			rendered = append(rendered, nil)
+			continue
+		}
+
+		rendered = append(rendered, []any{filter.String(), filter.Values()})
+	}
+
+	encoded, err := json.Marshal(rendered)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+func (r VerifiableRepository[T]) cursorMac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, r.cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func newCursorKey() []byte {
+	key := make([]byte, 32)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(key)
+	return key
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// controls what list methods do with records that fail verification
+type VerificationPolicy int
+
+const (
+	// fail on the first invalid record. this is the default.
+	VerifyStrict VerificationPolicy = iota
+	// return every record, invalid ones included, along with a *VerificationReport error
+	VerifyCollect
+	// return only the valid records, along with a *VerificationReport error
+	VerifySkipInvalid
+	// don't verify at all, for trusted bulk work like migrations
+	VerifyOff
+)
+
+func (p VerificationPolicy) String() string {
+	switch p {
+	case VerifyStrict:
+		return "strict"
+	case VerifyCollect:
+		return "collect"
+	case VerifySkipInvalid:
+		return "skip-invalid"
+	case VerifyOff:
+		return "off"
+	default:
+		return fmt.Sprintf("VerificationPolicy(%d)", int(p))
+	}
+}
+
+// returned by list methods under VerifyCollect and VerifySkipInvalid when any record failed
+// verification. the records are still populated according to the policy, so check for it with
+// errors.As before treating the call as failed.
+type VerificationReport struct {
+	Checked        int             `json:"checked"`
+	InvalidRecords []InvalidRecord `json:"invalidRecords"`
+
+	errs []error
+}
+
+func (r *VerificationReport) Error() string {
+	return fmt.Sprintf("%d of %d records failed verification", len(r.InvalidRecords), r.Checked)
+}
+
+// exposes the underlying failures, so errors.Is(err, algorithms.ErrTamperDetected) works
+func (r *VerificationReport) Unwrap() []error {
+	return r.errs
+}
+
+type verificationPolicyKey struct{}
+
+// overrides the repository's policy for calls made with the returned context
+func WithVerificationPolicy(ctx context.Context, policy VerificationPolicy) context.Context {
+	return context.WithValue(ctx, verificationPolicyKey{}, policy)
+}
+
+func (r *VerifiableRepository[T]) SetVerificationPolicy(policy VerificationPolicy) {
+	r.policy = policy
+}
+
+// list methods verify records on up to this many goroutines, preserving order and reporting the
+// same errors as serial verification. values below 2 verify serially, which is the default.
+func (r *VerifiableRepository[T]) SetVerificationWorkers(workers int) {
+	r.workers = workers
+}
+
+// helpers
+
+type verificationScopeKey struct{}
+
+// what verifying one record establishes for the others read along with it, like the identity that
+// controls each chain
+type verificationScope struct {
+	mu          sync.Mutex
+	controllers map[string]string
+}
+
+func withVerificationScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verificationScopeKey{}, &verificationScope{controllers: map[string]string{}})
+}
+
+// nil outside a list or stream, which remembers nothing
+func verificationScopeFrom(ctx context.Context) *verificationScope {
+	scope, _ := ctx.Value(verificationScopeKey{}).(*verificationScope)
+	return scope
+}
+
+func (s *verificationScope) controller(prefix string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	controller, ok := s.controllers[prefix]
+	return controller, ok
+}
+
+func (s *verificationScope) rememberController(prefix, controller string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.controllers[prefix] = controller
+}
+
+func (r VerifiableRepository[T]) verificationPolicy(ctx context.Context) VerificationPolicy {
+	if policy, ok := ctx.Value(verificationPolicyKey{}).(VerificationPolicy); ok {
+		return policy
+	}
+
+	return r.policy
+}
+
+// for reads the repository makes on its own behalf, like the version a new one is chained onto.
+// these are always verified, whatever policy the caller set.
+func strictly(ctx context.Context) context.Context {
+	return WithVerificationPolicy(ctx, VerifyStrict)
+}
+
+// single records have nothing to report alongside, so only VerifyOff changes how they are treated
+func (r VerifiableRepository[T]) verify(ctx context.Context, record T, verify func(context.Context, T) error) error {
+	if r.verificationPolicy(ctx) == VerifyOff {
+		return nil
+	}
+
+	return verify(ctx, record)
+}
+
+func (r VerifiableRepository[T]) verifyRecords(ctx context.Context, records *[]T, verify func(context.Context, T) error) error {
+	policy := r.verificationPolicy(ctx)
+	if policy == VerifyOff {
+		return nil
+	}
+
+	errs := r.verifyAll(withVerificationScope(ctx), *records, verify, policy == VerifyStrict)
+
+	if policy == VerifyStrict {
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	report := &VerificationReport{Checked: len(*records)}
+	valid := []T{}
+
+	for i, record := range *records {
+		if err := errs[i]; err != nil {
+			report.InvalidRecords = append(report.InvalidRecords, InvalidRecord{
+				Id:             record.GetId(),
+				SequenceNumber: record.GetSequenceNumber(),
+				Reason:         err.Error(),
+			})
+			report.errs = append(report.errs, err)
+
+			if policy == VerifySkipInvalid {
+				continue
+			}
+		}
+
+		valid = append(valid, record)
+	}
+
+	*records = valid
+
+	if len(report.InvalidRecords) > 0 {
+		return report
+	}
+
+	return nil
+}
+
+// returns the verification error of each record by index. with failFast, records after the earliest
+// failure seen so far may be left unverified, which never changes which error comes first.
+func (r VerifiableRepository[T]) verifyAll(ctx context.Context, records []T, verify func(context.Context, T) error, failFast bool) []error {
+	errs := make([]error, len(records))
+	workers := min(r.workers, len(records))
+
+	if workers <= 1 {
+		for i, record := range records {
+			errs[i] = verify(ctx, record)
+			if errs[i] != nil && failFast {
+				break
+			}
+		}
+
+		return errs
+	}
+
+	var next atomic.Int64
+	var earliestFailure atomic.Int64
+	earliestFailure.Store(int64(len(records)))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for {
+				i := next.Add(1) - 1
+				if i >= int64(len(records)) || failFast && i > earliestFailure.Load() {
+					return
+				}
+
+				if errs[i] = verify(ctx, records[i]); errs[i] != nil {
+					for {
+						earliest := earliestFailure.Load()
+						if i >= earliest || earliestFailure.CompareAndSwap(earliest, i) {
+							break
+						}
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func isVerificationReport(err error) bool {
+	var report *VerificationReport
+	return errors.As(err, &report)
+}
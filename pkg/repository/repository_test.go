@@ -3,16 +3,22 @@ package repository_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
 	data "github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
 )
 
 type DeterministicModel struct {
@@ -106,6 +112,61 @@ CREATE TABLE IF NOT EXISTS signable (
 );
 `
 
+type MultiSignableModel struct {
+	primitives.MultiSignableRecorder
+	Foo string `db:"foo" json:"foo"`
+	Bar string `db:"bar" json:"bar"`
+}
+
+func (*MultiSignableModel) TableName() string {
+	return `multisignable`
+}
+
+var MULTISIGNABLE_TABLE_SQL = `
+CREATE TABLE IF NOT EXISTS multisignable (
+	-- Standard fields
+    id              	TEXT PRIMARY KEY,
+	prefix				TEXT NOT NULL,
+	previous        	TEXT,
+	sequence_number 	BIGINT NOT NULL,
+
+	-- Optional fields
+	created_at          DATETIME NOT NULL,
+	nonce           	TEXT NOT NULL,
+	signing_identities	TEXT NOT NULL,
+	signatures       	TEXT NOT NULL,
+
+	-- Model-specific fields
+	foo 				TEXT NOT NULL,
+	bar                 TEXT NOT NULL,
+
+	-- Uniqueness constraint for sequence numbers
+	UNIQUE(prefix, sequence_number)
+);
+`
+
+// the package name is shadowed by local repository variables throughout these tests
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrNotFound)
+}
+
+func isSequenceConflict(err error) bool {
+	return errors.Is(err, repository.ErrSequenceConflict)
+}
+
+func isDuplicateRecord(err error) bool {
+	return errors.Is(err, repository.ErrDuplicateRecord)
+}
+
+func isInvalidCursor(err error) bool {
+	return errors.Is(err, repository.ErrInvalidCursor)
+}
+
+func isVerificationReport(err error) bool {
+	var report *repository.VerificationReport
+	return errors.As(err, &report)
+}
+
 func TestDeterministicRepository(t *testing.T) {
 	repository, err := createDeterministicRepository()
 	if err != nil {
@@ -132,8 +193,19 @@ func TestDeterministicRepository(t *testing.T) {
 		Bar: "baz",
 	}
 
-	if err := repository.CreateVersion(context.Background(), record); err == nil {
-		fmt.Printf("unexpected successful version creation in deterministic repository")
+	if err := repository.CreateVersion(context.Background(), record); !isDuplicateRecord(err) {
+		fmt.Printf("unexpected result for version creation in deterministic repository: %v\n", err)
+		t.FailNow()
+	}
+
+	// batches report the duplicate too, though a multi-row insert doesn't say which row it was
+	record = &DeterministicModel{
+		Foo: "bar",
+		Bar: "baz",
+	}
+
+	if err := repository.CreateVersions(context.Background(), []*DeterministicModel{{Foo: "new"}, record}); !isDuplicateRecord(err) {
+		fmt.Printf("unexpected result for batch creation in deterministic repository: %v\n", err)
 		t.FailNow()
 	}
 
@@ -184,7 +256,7 @@ func createDeterministicRepository() (repository.Repository[*DeterministicModel]
 		return nil, err
 	}
 
-	_, err = store.Sql().ExecContext(ctx, DETERMINISTIC_TABLE_SQL)
+	_, err = store.Sql(ctx).ExecContext(ctx, DETERMINISTIC_TABLE_SQL)
 	if err != nil {
 		return nil, err
 	}
@@ -238,7 +310,7 @@ func createVerifiableRepository() (repository.Repository[*VerifiableModel], erro
 		return nil, err
 	}
 
-	_, err = store.Sql().ExecContext(ctx, VERIFIABLE_TABLE_SQL)
+	_, err = store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL)
 	if err != nil {
 		return nil, err
 	}
@@ -293,7 +365,7 @@ func createSignableRepository() (repository.Repository[*SignableModel], error) {
 		return nil, err
 	}
 
-	_, err = store.Sql().ExecContext(ctx, SIGNABLE_TABLE_SQL)
+	_, err = store.Sql(ctx).ExecContext(ctx, SIGNABLE_TABLE_SQL)
 	if err != nil {
 		return nil, err
 	}
@@ -325,6 +397,96 @@ func createSignableRepository() (repository.Repository[*SignableModel], error) {
 	return repository, nil
 }
 
+func TestMultiSignableRepository(t *testing.T) {
+	store, keys, verificationKeyStore, err := createMultiSignableStore()
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+
+	threshold, err := algorithms.NewThreshold(2, identities(keys)...)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+
+	repository := repository.NewMultiSignableRepository[*MultiSignableModel](
+		store,
+		true,
+		true,
+		examples.NewNoncer(),
+		threshold,
+		keys[1:],
+		verificationKeyStore,
+	)
+
+	record := &MultiSignableModel{
+		Foo: "bar",
+		Bar: "baz",
+	}
+
+	buffers := []*MultiSignableModel{{}, {}, {}, {}}
+
+	if err := exerciseRepository(repository, record, buffers); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+
+	if len(record.SigningIdentities) != 3 || len(record.Signatures) != 2 {
+		fmt.Printf("unexpected signers: %v %v\n", record.SigningIdentities, record.Signatures)
+		t.FailNow()
+	}
+}
+
+// an ed25519, a P-256 and a P-384 key, all trusted by the returned key store
+func createMultiSignableStore() (*data.SQLiteStore, []interfaces.SigningKey, *examples.VerificationKeyStore, error) {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	_, err = store.Sql(ctx).ExecContext(ctx, MULTISIGNABLE_TABLE_SQL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ed25519Key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	p256Key, err := examples.NewECDSAP256(nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	p384Key, err := examples.NewECDSAP384(nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	keys := []interfaces.SigningKey{ed25519Key, p256Key, p384Key}
+
+	verificationKeyStore := examples.NewVerificationKeyStore()
+	for i, identity := range identities(keys) {
+		verificationKeyStore.Add(identity, keys[i])
+	}
+
+	return store, keys, verificationKeyStore, nil
+}
+
+func identities(keys []interfaces.SigningKey) []string {
+	identities := []string{}
+	for _, key := range keys {
+		identity, _ := key.Identity()
+		identities = append(identities, identity)
+	}
+
+	return identities
+}
+
 func exerciseRepository[T primitives.VerifiableAndRecordable](repository repository.Repository[T], record2 T, buffers []T) error {
 	ctx := context.Background()
 
@@ -509,8 +671,8 @@ func exerciseRepository[T primitives.VerifiableAndRecordable](repository reposit
 		return fmt.Errorf("unexpected number of record returned: %d != %d", len(noRecords), 0)
 	}
 
-	if err := repository.Get(ctx, record2, expressions.Equal("foo", "foo"), nil); err == nil {
-		return fmt.Errorf("expected an error for get with no results")
+	if err := repository.Get(ctx, record2, expressions.Equal("foo", "foo"), nil); !isNotFound(err) {
+		return fmt.Errorf("expected not found for get with no results: %v", err)
 	}
 
 	twoRecords := []T{}
@@ -542,3 +704,1462 @@ func exerciseRepository[T primitives.VerifiableAndRecordable](repository reposit
 
 	return nil
 }
+
+func TestVerifyChain(t *testing.T) {
+	if err := testVerifyChain(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testVerifyChain() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	repository := repository.NewVerifiableRepository[*VerifiableModel](
+		store,
+		true,
+		true,
+		examples.NewNoncer(),
+	)
+
+	record := &VerifiableModel{
+		Foo: "bar",
+		Bar: "baz",
+	}
+
+	for range 4 {
+		if err := repository.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	report, err := repository.VerifyChain(ctx, record.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if !report.Intact() || report.Length != 4 {
+		return fmt.Errorf("unexpected report for intact chain: %+v", report)
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET foo = 'tampered' WHERE sequence_number = 3"); err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, "DELETE FROM verifiable WHERE sequence_number = 1"); err != nil {
+		return err
+	}
+
+	report, err = repository.VerifyChain(ctx, record.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if report.Intact() {
+		return fmt.Errorf("unexpected intact report for damaged chain")
+	}
+
+	if len(report.InvalidRecords) != 1 || report.InvalidRecords[0].SequenceNumber != 3 {
+		return fmt.Errorf("unexpected invalid records: %+v", report.InvalidRecords)
+	}
+
+	if len(report.Gaps) != 1 || report.Gaps[0].From != 1 || report.Gaps[0].To != 1 {
+		return fmt.Errorf("unexpected gaps: %+v", report.Gaps)
+	}
+
+	if len(report.BrokenLinks) != 0 {
+		return fmt.Errorf("unexpected broken links: %+v", report.BrokenLinks)
+	}
+
+	if _, err := repository.VerifyChain(ctx, "missing"); !isNotFound(err) {
+		return fmt.Errorf("expected not found verifying a missing chain: %v", err)
+	}
+
+	return nil
+}
+
+func TestChainDamage(t *testing.T) {
+	if err := testChainDamage(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testChainDamage() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	// without the uniqueness constraint a chain can fork
+	unconstrained := strings.Replace(VERIFIABLE_TABLE_SQL, ",\n\n\t-- Uniqueness constraint for sequence numbers\n\tUNIQUE(prefix, sequence_number)", "", 1)
+	if unconstrained == VERIFIABLE_TABLE_SQL {
+		return fmt.Errorf("couldn't drop the uniqueness constraint")
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, unconstrained); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	record := &VerifiableModel{
+		Foo: "bar",
+		Bar: "baz",
+	}
+
+	ids := []string{}
+	for range 4 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+
+		ids = append(ids, record.GetId())
+	}
+
+	prefix := record.GetPrefix()
+
+	// a second version following sequence number 1
+	fork := &VerifiableModel{}
+	if err := r.GetBySequenceNumber(ctx, fork, prefix, 1); err != nil {
+		return err
+	}
+
+	fork.Foo = "fork"
+	preparer := repository.NewVerifiableRepository[*VerifiableModel](store, false, true, examples.NewNoncer())
+	if err := preparer.CreateVersion(ctx, fork); err != nil {
+		return err
+	}
+
+	columns := []string{"id", "prefix", "previous", "sequence_number", "created_at", "nonce", "foo", "bar"}
+	if _, err := store.Sql(ctx).NamedExecContext(ctx, store.Dialect().Insert("verifiable", columns), fork); err != nil {
+		return err
+	}
+
+	report, err := r.VerifyChain(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Equal(report.DuplicateSequenceNumbers, []uint64{2}) || report.Length != 5 || report.Intact() {
+		return fmt.Errorf("expected the fork to be reported: %+v", report)
+	}
+
+	if len(report.InvalidRecords) != 0 || len(report.BrokenLinks) != 0 || len(report.PrefixMismatches) != 0 {
+		return fmt.Errorf("unexpected damage reported for a fork: %+v", report)
+	}
+
+	// pointing a version elsewhere breaks its link
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET previous = ? WHERE id = ?", ids[0], ids[3]); err != nil {
+		return err
+	}
+
+	report, err = r.VerifyChain(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if len(report.BrokenLinks) != 1 || report.BrokenLinks[0].Id != ids[3] || *report.BrokenLinks[0].Previous != ids[0] {
+		return fmt.Errorf("expected the repointed version's link to be reported broken: %+v", report.BrokenLinks)
+	}
+
+	// a version moved under another prefix leaves a gap, and is found by the version it follows
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET prefix = 'elsewhere' WHERE id = ?", ids[1]); err != nil {
+		return err
+	}
+
+	report, err = r.VerifyChain(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Equal(report.PrefixMismatches, []string{ids[1]}) {
+		return fmt.Errorf("expected the moved version to be reported: %+v", report.PrefixMismatches)
+	}
+
+	if len(report.Gaps) != 1 || report.Gaps[0].From != 1 || report.Gaps[0].To != 1 {
+		return fmt.Errorf("unexpected gaps: %+v", report.Gaps)
+	}
+
+	// so is the first version, by its id, along with the moved versions that follow it
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET prefix = 'elsewhere' WHERE id = ?", ids[0]); err != nil {
+		return err
+	}
+
+	report, err = r.VerifyChain(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Equal(report.PrefixMismatches, []string{ids[0], ids[1]}) {
+		return fmt.Errorf("expected both moved versions to be reported: %+v", report.PrefixMismatches)
+	}
+
+	return nil
+}
+
+func TestVersionConflict(t *testing.T) {
+	if err := testVersionConflict(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testVersionConflict() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	record := &SignableModel{
+		Foo: "bar",
+		Bar: "baz",
+	}
+
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	// two writers start from the same version
+	first := &SignableModel{}
+	second := &SignableModel{}
+
+	if err := r.GetLatestByPrefix(ctx, first, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	if err := r.GetLatestByPrefix(ctx, second, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	first.Foo = "first"
+	if err := r.CreateVersion(ctx, first); err != nil {
+		return err
+	}
+
+	second.Foo = "second"
+	err = r.CreateVersion(ctx, second)
+	if !isSequenceConflict(err) {
+		return fmt.Errorf("expected a sequence conflict: %v", err)
+	}
+
+	var conflict repository.VersionConflictError[*SignableModel]
+	if !errors.As(err, &conflict) {
+		return fmt.Errorf("expected a version conflict: %T", err)
+	}
+
+	if !strings.EqualFold(conflict.Latest.GetId(), first.GetId()) || conflict.Latest.Foo != "first" {
+		return fmt.Errorf("unexpected latest in conflict: %s", conflict.Latest.GetId())
+	}
+
+	var sequenceConflict repository.SequenceConflictError
+	if !errors.As(err, &sequenceConflict) {
+		return fmt.Errorf("expected a version conflict to be a sequence conflict: %T", err)
+	}
+
+	if sequenceConflict.Prefix != record.GetPrefix() || sequenceConflict.SequenceNumber != first.GetSequenceNumber() {
+		return fmt.Errorf("unexpected sequence conflict position: %s/%d", sequenceConflict.Prefix, sequenceConflict.SequenceNumber)
+	}
+
+	// the losing record should be untouched
+	if !strings.EqualFold(second.GetId(), record.GetId()) || second.GetSequenceNumber() != 0 || second.GetPrevious() != nil {
+		return fmt.Errorf("record was not restored after conflict")
+	}
+
+	// a competing write during the first attempt forces a retry
+	attempts := 0
+	updated, err := r.UpdateWithRetry(ctx, record.GetPrefix(), func(latest *SignableModel) error {
+		attempts++
+
+		if attempts == 1 {
+			competitor := &SignableModel{}
+			if err := r.GetLatestByPrefix(ctx, competitor, record.GetPrefix()); err != nil {
+				return err
+			}
+
+			competitor.Foo = "competitor"
+			if err := r.CreateVersion(ctx, competitor); err != nil {
+				return err
+			}
+		}
+
+		latest.Bar = "updated"
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if attempts != 2 {
+		return fmt.Errorf("unexpected number of attempts: %d", attempts)
+	}
+
+	if updated.GetSequenceNumber() != 3 || updated.Foo != "competitor" || updated.Bar != "updated" {
+		return fmt.Errorf("unexpected updated record: %d %s %s", updated.GetSequenceNumber(), updated.Foo, updated.Bar)
+	}
+
+	return nil
+}
+
+func TestPagination(t *testing.T) {
+	if err := testPagination(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testPagination() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	// 5 chains of 3 versions, so sequence numbers tie heavily
+	for i := range 5 {
+		record := &SignableModel{
+			Foo: "paged",
+			Bar: fmt.Sprintf("%d", i),
+		}
+
+		for range 3 {
+			if err := r.CreateVersion(ctx, record); err != nil {
+				return err
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	var previous uint64
+	cursor := ""
+	pages := 0
+
+	for {
+		page := []*SignableModel{}
+		cursor, err = r.SelectPage(ctx, &page, expressions.Equal("foo", "paged"), orderings.Ascending("sequence_number"), 4, cursor)
+		if err != nil {
+			return err
+		}
+
+		pages++
+
+		for _, record := range page {
+			if seen[record.GetId()] {
+				return fmt.Errorf("record %s returned twice", record.GetId())
+			}
+
+			if record.GetSequenceNumber() < previous {
+				return fmt.Errorf("records out of order")
+			}
+
+			seen[record.GetId()] = true
+			previous = record.GetSequenceNumber()
+		}
+
+		if cursor == "" {
+			break
+		}
+	}
+
+	if len(seen) != 15 || pages != 4 {
+		return fmt.Errorf("unexpected paging: %d records in %d pages", len(seen), pages)
+	}
+
+	latest := []*SignableModel{}
+	cursor, err = r.ListLatestByPrefixPage(ctx, &latest, expressions.Equal("foo", "paged"), nil, orderings.Descending("bar"), 2, "")
+	if err != nil {
+		return err
+	}
+
+	if len(latest) != 2 || latest[0].Bar != "4" || latest[1].Bar != "3" {
+		return fmt.Errorf("unexpected first page of latest records")
+	}
+
+	more := []*SignableModel{}
+	if _, err := r.ListLatestByPrefixPage(ctx, &more, expressions.Equal("foo", "paged"), nil, orderings.Descending("bar"), 2, cursor); err != nil {
+		return err
+	}
+
+	if len(more) != 2 || more[0].Bar != "2" || more[1].Bar != "1" || more[0].GetSequenceNumber() != 2 {
+		return fmt.Errorf("unexpected second page of latest records")
+	}
+
+	// cursors can't be forged or reused with a different ordering
+	tampered := []byte(cursor)
+	tampered[5] ^= 1
+
+	if _, err := r.ListLatestByPrefixPage(ctx, &more, expressions.Equal("foo", "paged"), nil, orderings.Descending("bar"), 2, string(tampered)); !isInvalidCursor(err) {
+		return fmt.Errorf("expected invalid cursor for tampered cursor: %v", err)
+	}
+
+	if _, err := r.ListLatestByPrefixPage(ctx, &more, expressions.Equal("foo", "paged"), nil, orderings.Ascending("bar"), 2, cursor); !isInvalidCursor(err) {
+		return fmt.Errorf("expected invalid cursor for a different ordering: %v", err)
+	}
+
+	// nor with different filters
+	if _, err := r.ListLatestByPrefixPage(ctx, &more, expressions.Equal("foo", "paged"), expressions.NotEqual("bar", "0"), orderings.Descending("bar"), 2, cursor); !isInvalidCursor(err) {
+		return fmt.Errorf("expected invalid cursor for a different condition: %v", err)
+	}
+
+	if _, err := r.ListLatestByPrefixPage(ctx, &more, expressions.Equal("foo", "other"), nil, orderings.Descending("bar"), 2, cursor); !isInvalidCursor(err) {
+		return fmt.Errorf("expected invalid cursor for a different pre-filter: %v", err)
+	}
+
+	if _, err := r.SelectPage(ctx, &more, expressions.Equal("foo", "paged"), orderings.Descending("bar"), 2, cursor); !isInvalidCursor(err) {
+		return fmt.Errorf("expected invalid cursor without the pre-filter: %v", err)
+	}
+
+	// a NULL can't be paged past, so nullable columns are refused before the first page
+	if _, err := r.SelectPage(ctx, &more, nil, orderings.Ascending("previous"), 100, ""); err == nil || isInvalidCursor(err) {
+		return fmt.Errorf("expected an error paging on a nullable column: %v", err)
+	}
+
+	// a cursor holds a single value, so composite orderings can't be paged
+	if _, err := r.SelectPage(ctx, &more, nil, orderings.Composite(orderings.Ascending("foo"), orderings.Ascending("bar")), 2, ""); err == nil {
+		return fmt.Errorf("expected an error paging on a composite ordering")
+	}
+
+	return nil
+}
+
+func TestPaginationWithinMillisecond(t *testing.T) {
+	if err := testPaginationWithinMillisecond(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testPaginationWithinMillisecond() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	for i := range 7 {
+		if err := r.CreateVersion(ctx, &VerifiableModel{Foo: "paged", Bar: fmt.Sprintf("%d", i)}); err != nil {
+			return err
+		}
+	}
+
+	// every record shares a millisecond, so page boundaries fall between equal timestamps
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET created_at = '2026-01-02T03:04:05.678Z'"); err != nil {
+		return err
+	}
+
+	ctx = repository.WithVerificationPolicy(ctx, repository.VerifyOff)
+
+	for _, descending := range []bool{false, true} {
+		ids := []string{}
+		cursor := ""
+
+		for {
+			page := []*VerifiableModel{}
+			if descending {
+				cursor, err = r.SelectPage(ctx, &page, nil, orderings.Descending("created_at"), 3, cursor)
+			} else {
+				cursor, err = r.SelectPage(ctx, &page, nil, orderings.Ascending("created_at"), 3, cursor)
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, record := range page {
+				ids = append(ids, record.GetId())
+			}
+
+			if cursor == "" {
+				break
+			}
+		}
+
+		// ties are broken by id
+		sorted := slices.Sorted(slices.Values(ids))
+		if descending {
+			slices.Reverse(sorted)
+		}
+
+		if len(ids) != 7 || !slices.Equal(ids, sorted) {
+			return fmt.Errorf("unexpected paging within a millisecond: %v", ids)
+		}
+	}
+
+	return nil
+}
+
+func TestStreaming(t *testing.T) {
+	if err := testStreaming(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testStreaming() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	record := &VerifiableModel{Foo: "streamed"}
+	for range 5 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET bar = 'tampered' WHERE sequence_number = 2"); err != nil {
+		return err
+	}
+
+	count := 0
+	failures := []uint64{}
+	for streamed, err := range r.ListByPrefixIter(ctx, record.GetPrefix()) {
+		if err != nil {
+			if streamed == nil {
+				return err
+			}
+
+			failures = append(failures, streamed.GetSequenceNumber())
+			continue
+		}
+
+		if streamed.GetSequenceNumber() != uint64(count+len(failures)) {
+			return fmt.Errorf("unexpected sequence number: %d", streamed.GetSequenceNumber())
+		}
+
+		count++
+	}
+
+	if count != 4 || len(failures) != 1 || failures[0] != 2 {
+		return fmt.Errorf("unexpected stream: %d verified, failures %v", count, failures)
+	}
+
+	// stopping early releases the connection, so later queries still work
+	for range r.SelectIter(ctx, expressions.Equal("foo", "streamed"), nil, nil) {
+		break
+	}
+
+	latest := 0
+	for streamed, err := range r.ListLatestByPrefixIter(ctx, expressions.Equal("foo", "streamed"), nil, nil, nil) {
+		if err != nil {
+			return err
+		}
+
+		if streamed.GetSequenceNumber() != 4 {
+			return fmt.Errorf("unexpected latest sequence number: %d", streamed.GetSequenceNumber())
+		}
+
+		latest++
+	}
+
+	if latest != 1 {
+		return fmt.Errorf("unexpected latest count: %d", latest)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	var cancellation error
+	for _, err := range r.ListByPrefixIter(cancelled, record.GetPrefix()) {
+		cancellation = err
+	}
+
+	if !errors.Is(cancellation, context.Canceled) {
+		return fmt.Errorf("expected cancellation: %v", cancellation)
+	}
+
+	return nil
+}
+
+func TestCreateVersions(t *testing.T) {
+	if err := testCreateVersions(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testCreateVersions() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	a := &SignableModel{Foo: "batch", Bar: "a"}
+	b := &SignableModel{Foo: "batch", Bar: "b"}
+
+	if err := r.CreateVersions(ctx, []*SignableModel{a, b, a, a}); err != nil {
+		return err
+	}
+
+	if a.GetSequenceNumber() != 2 || b.GetSequenceNumber() != 0 {
+		return fmt.Errorf("unexpected sequence numbers: %d, %d", a.GetSequenceNumber(), b.GetSequenceNumber())
+	}
+
+	report, err := r.VerifyChain(ctx, a.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if !report.Intact() || report.Length != 3 {
+		return fmt.Errorf("unexpected chain report: %+v", report)
+	}
+
+	// enough records to need several chunks
+	many := []*SignableModel{}
+	for i := range 300 {
+		many = append(many, &SignableModel{Foo: "many", Bar: fmt.Sprintf("%d", i)})
+	}
+
+	if err := r.CreateVersions(ctx, many); err != nil {
+		return err
+	}
+
+	loaded := []*SignableModel{}
+	if err := r.Select(ctx, &loaded, expressions.Equal("foo", "many"), nil, nil); err != nil {
+		return err
+	}
+
+	if len(loaded) != 300 {
+		return fmt.Errorf("unexpected record count: %d", len(loaded))
+	}
+
+	// a stale copy of b conflicts, so nothing in the batch is written
+	stale := &SignableModel{}
+	if err := r.GetById(ctx, stale, b.GetId()); err != nil {
+		return err
+	}
+
+	if err := r.CreateVersion(ctx, b); err != nil {
+		return err
+	}
+
+	c := &SignableModel{Foo: "batch", Bar: "c"}
+	aId := a.GetId()
+
+	err = r.CreateVersions(ctx, []*SignableModel{c, a, stale})
+
+	var conflict repository.SequenceConflictError
+	if !errors.As(err, &conflict) {
+		return fmt.Errorf("expected a sequence conflict: %v", err)
+	}
+
+	if conflict.Prefix != b.GetPrefix() || conflict.SequenceNumber != b.GetSequenceNumber() {
+		return fmt.Errorf("unexpected conflict position: %s/%d", conflict.Prefix, conflict.SequenceNumber)
+	}
+
+	if c.GetId() != "" || a.GetId() != aId || stale.GetSequenceNumber() != 0 {
+		return fmt.Errorf("records were not restored after a failed batch")
+	}
+
+	batch := []*SignableModel{}
+	if err := r.Select(ctx, &batch, expressions.Equal("foo", "batch"), nil, nil); err != nil {
+		return err
+	}
+
+	if len(batch) != 5 {
+		return fmt.Errorf("unexpected record count after failed batch: %d", len(batch))
+	}
+
+	// a copy of a record earlier in the batch is just as stale, as it would be for CreateVersion
+	copied := &SignableModel{}
+	if err := r.GetById(ctx, copied, aId); err != nil {
+		return err
+	}
+
+	err = r.CreateVersions(ctx, []*SignableModel{a, copied})
+	if !errors.As(err, &conflict) {
+		return fmt.Errorf("expected a sequence conflict for a copy earlier in the batch: %v", err)
+	}
+
+	if conflict.Prefix != a.GetPrefix() || conflict.SequenceNumber != 3 {
+		return fmt.Errorf("unexpected conflict position: %s/%d", conflict.Prefix, conflict.SequenceNumber)
+	}
+
+	if a.GetId() != aId || copied.GetId() != aId {
+		return fmt.Errorf("records were not restored after a stale copy")
+	}
+
+	return nil
+}
+
+func TestCount(t *testing.T) {
+	if err := testCount(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testCount() error {
+	ctx := context.Background()
+
+	r, err := createVerifiableRepository()
+	if err != nil {
+		return err
+	}
+
+	a := &VerifiableModel{Foo: "count", Bar: "a"}
+	b := &VerifiableModel{Foo: "count", Bar: "b"}
+
+	if err := r.CreateVersions(ctx, []*VerifiableModel{a, b, a}); err != nil {
+		return err
+	}
+
+	count, err := r.Count(ctx, expressions.Equal("foo", "count"))
+	if err != nil {
+		return err
+	}
+
+	if count != 3 {
+		return fmt.Errorf("unexpected count: %d", count)
+	}
+
+	count, err = r.CountLatestByPrefix(ctx, expressions.Equal("foo", "count"), nil)
+	if err != nil {
+		return err
+	}
+
+	if count != 2 {
+		return fmt.Errorf("unexpected latest count: %d", count)
+	}
+
+	count, err = r.CountLatestByPrefix(ctx, expressions.Equal("foo", "count"), expressions.Equal("bar", "a"))
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return fmt.Errorf("unexpected filtered latest count: %d", count)
+	}
+
+	exists, err := r.Exists(ctx, expressions.Equal("prefix", a.GetPrefix()))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("expected prefix to exist")
+	}
+
+	exists, err = r.Exists(ctx, expressions.Equal("prefix", "Enope"))
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("expected prefix not to exist")
+	}
+
+	return nil
+}
+
+func TestAsOf(t *testing.T) {
+	if err := testAsOf(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testAsOf() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	pause := func() primitives.Timestamp {
+		time.Sleep(5 * time.Millisecond)
+		now := primitives.Timestamp(time.Now())
+		time.Sleep(5 * time.Millisecond)
+		return now
+	}
+
+	before := pause()
+
+	a := &SignableModel{Foo: "asof", Bar: "active"}
+	b := &SignableModel{Foo: "asof", Bar: "active"}
+	if err := r.CreateVersions(ctx, []*SignableModel{a, b}); err != nil {
+		return err
+	}
+
+	reportDate := pause()
+
+	a.Bar = "inactive"
+	if err := r.CreateVersion(ctx, a); err != nil {
+		return err
+	}
+
+	historical := &SignableModel{}
+	if err := r.GetLatestByPrefixAsOf(ctx, historical, a.GetPrefix(), reportDate); err != nil {
+		return err
+	}
+
+	if historical.GetSequenceNumber() != 0 || historical.Bar != "active" {
+		return fmt.Errorf("unexpected historical version: %d (%s)", historical.GetSequenceNumber(), historical.Bar)
+	}
+
+	if err := r.GetLatestByPrefixAsOf(ctx, historical, a.GetPrefix(), before); !isNotFound(err) {
+		return fmt.Errorf("expected not found before the chain existed: %v", err)
+	}
+
+	active := []*SignableModel{}
+	if err := r.ListLatestByPrefixAsOf(ctx, &active, expressions.Equal("foo", "asof"), expressions.Equal("bar", "active"), nil, nil, reportDate); err != nil {
+		return err
+	}
+
+	if len(active) != 2 {
+		return fmt.Errorf("unexpected historical active count: %d", len(active))
+	}
+
+	active = []*SignableModel{}
+	if err := r.ListLatestByPrefix(ctx, &active, expressions.Equal("foo", "asof"), expressions.Equal("bar", "active"), nil, nil); err != nil {
+		return err
+	}
+
+	if len(active) != 1 {
+		return fmt.Errorf("unexpected current active count: %d", len(active))
+	}
+
+	return nil
+}
+
+func TestDiff(t *testing.T) {
+	if err := testDiff(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testDiff() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	record := &SignableModel{Foo: "diff", Bar: "before"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	record.Bar = "middle"
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	record.Bar = "after"
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	diff, err := r.Diff(ctx, record.GetPrefix(), 0, 2, false)
+	if err != nil {
+		return err
+	}
+
+	if len(diff.Changes) != 1 ||
+		diff.Changes[0].Column != "bar" ||
+		diff.Changes[0].Before != "before" ||
+		diff.Changes[0].After != "after" {
+		return fmt.Errorf("unexpected changes: %+v", diff.Changes)
+	}
+
+	if diff.FromSequenceNumber != 0 || diff.ToSequenceNumber != 2 || diff.ToId != record.GetId() {
+		return fmt.Errorf("unexpected diff versions: %+v", diff)
+	}
+
+	encoded, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(string(encoded), `{"column":"bar","json":"bar","before":"before","after":"after"}`) {
+		return fmt.Errorf("unexpected encoding: %s", encoded)
+	}
+
+	diff, err = r.Diff(ctx, record.GetPrefix(), 0, 1, true)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{}
+	for _, change := range diff.Changes {
+		columns = append(columns, change.Column)
+	}
+
+	if !slices.Contains(columns, "id") ||
+		!slices.Contains(columns, "sequence_number") ||
+		!slices.Contains(columns, "previous") ||
+		!slices.Contains(columns, "bar") ||
+		slices.Contains(columns, "prefix") ||
+		slices.Contains(columns, "foo") {
+		return fmt.Errorf("unexpected bookkeeping changes: %v", columns)
+	}
+
+	if _, err := r.Diff(ctx, record.GetPrefix(), 0, 3, false); !isNotFound(err) {
+		return fmt.Errorf("expected not found: %v", err)
+	}
+
+	return nil
+}
+
+func TestVerificationPolicy(t *testing.T) {
+	if err := testVerificationPolicy(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testVerificationPolicy() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	record := &VerifiableModel{Foo: "policy"}
+	for range 5 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET bar = 'tampered' WHERE sequence_number IN (1, 3)"); err != nil {
+		return err
+	}
+
+	records := []*VerifiableModel{}
+	if err := r.ListByPrefix(ctx, &records, record.GetPrefix()); !errors.Is(err, algorithms.ErrTamperDetected) || isVerificationReport(err) {
+		return fmt.Errorf("expected strict failure: %v", err)
+	}
+
+	r.SetVerificationPolicy(repository.VerifySkipInvalid)
+
+	records = []*VerifiableModel{}
+	err = r.ListByPrefix(ctx, &records, record.GetPrefix())
+
+	var report *repository.VerificationReport
+	if !errors.As(err, &report) || !errors.Is(err, algorithms.ErrTamperDetected) {
+		return fmt.Errorf("expected a verification report: %v", err)
+	}
+
+	if report.Checked != 5 || len(report.InvalidRecords) != 2 || report.InvalidRecords[1].SequenceNumber != 3 {
+		return fmt.Errorf("unexpected report: %+v", report)
+	}
+
+	if len(records) != 3 {
+		return fmt.Errorf("unexpected valid record count: %d", len(records))
+	}
+
+	// a per-call policy overrides the repository's
+	records = []*VerifiableModel{}
+	err = r.ListByPrefix(repository.WithVerificationPolicy(ctx, repository.VerifyCollect), &records, record.GetPrefix())
+	if !isVerificationReport(err) || len(records) != 5 {
+		return fmt.Errorf("expected every record with a report: %d, %v", len(records), err)
+	}
+
+	records = []*VerifiableModel{}
+	if err := r.ListByPrefix(repository.WithVerificationPolicy(ctx, repository.VerifyOff), &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	if len(records) != 5 {
+		return fmt.Errorf("unexpected unverified record count: %d", len(records))
+	}
+
+	// skipping shortens pages, but the cursor still advances past the invalid records
+	seen := 0
+	cursor := ""
+	for {
+		page := []*VerifiableModel{}
+		cursor, err = r.SelectPage(ctx, &page, expressions.Equal("foo", "policy"), orderings.Ascending("sequence_number"), 2, cursor)
+		if err != nil && !isVerificationReport(err) {
+			return err
+		}
+
+		seen += len(page)
+		if cursor == "" {
+			break
+		}
+	}
+
+	if seen != 3 {
+		return fmt.Errorf("unexpected paged record count: %d", seen)
+	}
+
+	// the version a new one is chained onto is verified whatever the caller's policy
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET bar = 'tampered' WHERE sequence_number = 4"); err != nil {
+		return err
+	}
+
+	off := repository.WithVerificationPolicy(ctx, repository.VerifyOff)
+	if _, err := r.UpdateWithRetry(off, record.GetPrefix(), func(latest *VerifiableModel) error {
+		latest.Foo = "chained"
+		return nil
+	}); !errors.Is(err, algorithms.ErrTamperDetected) {
+		return fmt.Errorf("expected an update onto a tampered version to fail: %v", err)
+	}
+
+	stale := &VerifiableModel{}
+	if err := r.GetBySequenceNumber(off, stale, record.GetPrefix(), 3); err != nil {
+		return err
+	}
+
+	var conflict repository.VersionConflictError[*VerifiableModel]
+	if err := r.CreateVersion(off, stale); !isSequenceConflict(err) || errors.As(err, &conflict) {
+		return fmt.Errorf("expected a conflict without the tampered latest version: %v", err)
+	}
+
+	return nil
+}
+
+func TestParallelVerification(t *testing.T) {
+	if err := testParallelVerification(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testParallelVerification() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	record := &VerifiableModel{Foo: "parallel"}
+	for range 50 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	records := []*VerifiableModel{}
+	r.SetVerificationWorkers(8)
+	if err := r.ListByPrefix(ctx, &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	for i, loaded := range records {
+		if loaded.GetSequenceNumber() != uint64(i) {
+			return fmt.Errorf("order not preserved at %d: %d", i, loaded.GetSequenceNumber())
+		}
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, "UPDATE verifiable SET bar = 'tampered' WHERE sequence_number IN (7, 23, 41)"); err != nil {
+		return err
+	}
+
+	results := []string{}
+	for _, workers := range []int{1, 8} {
+		r.SetVerificationWorkers(workers)
+
+		records = []*VerifiableModel{}
+		strictErr := r.ListByPrefix(ctx, &records, record.GetPrefix())
+
+		records = []*VerifiableModel{}
+		var report *repository.VerificationReport
+		if err := r.ListByPrefix(repository.WithVerificationPolicy(ctx, repository.VerifySkipInvalid), &records, record.GetPrefix()); !errors.As(err, &report) {
+			return fmt.Errorf("expected a verification report: %v", err)
+		}
+
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, fmt.Sprintf("%v %s %d", strictErr, encoded, len(records)))
+	}
+
+	if results[0] != results[1] {
+		return fmt.Errorf("parallel verification differs from serial:\n%s\n%s", results[0], results[1])
+	}
+
+	if !strings.Contains(results[0], `"sequenceNumber":7`) || strings.Count(results[0], `"sequenceNumber"`) != 3 {
+		return fmt.Errorf("unexpected report: %s", results[0])
+	}
+
+	return nil
+}
+
+func TestMixedHashers(t *testing.T) {
+	if err := testMixedHashers(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testMixedHashers() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, VERIFIABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	r := repository.NewVerifiableRepository[*VerifiableModel](store, true, true, examples.NewNoncer())
+
+	record := &VerifiableModel{Foo: "hashed"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	r.SetHasher(examples.NewSha256())
+
+	for range 2 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	records := []*VerifiableModel{}
+	if err := r.ListByPrefix(ctx, &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	codes := ""
+	for _, loaded := range records {
+		codes += loaded.GetId()[:1]
+	}
+
+	if codes != "EII" {
+		return fmt.Errorf("unexpected derivation codes: %s", codes)
+	}
+
+	report, err := r.VerifyChain(ctx, record.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if !report.Intact() {
+		return fmt.Errorf("unexpected chain report: %+v", report)
+	}
+
+	// accepted hashers belong to the repository, so another one reading the table must opt in
+	reader := repository.NewVerifiableRepository[*VerifiableModel](store, false, true, examples.NewNoncer())
+	if err := reader.ListByPrefix(ctx, &records, record.GetPrefix()); !errors.Is(err, algorithms.ErrUnknownHashAlgorithm) {
+		return fmt.Errorf("expected sha-256 ids to be rejected: %v", err)
+	}
+
+	reader.AcceptHashers(examples.NewSha256())
+	if err := reader.ListByPrefix(ctx, &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func TestCanonicalization(t *testing.T) {
+	if err := testCanonicalization(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testCanonicalization() error {
+	ctx := context.Background()
+
+	r, err := createSignableRepository()
+	if err != nil {
+		return err
+	}
+
+	jcs := r.(*repository.SignableRepository[*SignableModel])
+	jcs.SetCanonicalizer(algorithms.JCS{})
+
+	record := &SignableModel{Foo: "canonical", Bar: "a"}
+	for range 3 {
+		if err := r.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	records := []*SignableModel{}
+	if err := r.ListByPrefix(ctx, &records, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	if len(records) != 3 {
+		return fmt.Errorf("unexpected record count: %d", len(records))
+	}
+
+	// the canonicalization isn't recorded in the data, so a mismatched repository can't verify
+	jcs.SetCanonicalizer(nil)
+
+	if err := r.GetLatestByPrefix(ctx, &SignableModel{}, record.GetPrefix()); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected verification to fail with the default canonicalizer: %v", err)
+	}
+
+	return nil
+}
+
+func TestThresholds(t *testing.T) {
+	if err := testThresholds(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testThresholds() error {
+	ctx := context.Background()
+
+	store, keys, verificationKeyStore, err := createMultiSignableStore()
+	if err != nil {
+		return err
+	}
+
+	twoOfThree, err := algorithms.NewThreshold(2, identities(keys)...)
+	if err != nil {
+		return err
+	}
+
+	// the first key carries the full weight on its own, the others need each other
+	weighted, err := algorithms.NewWeightedThreshold(identities(keys), []string{"1", "1/2", "1/2"})
+	if err != nil {
+		return err
+	}
+
+	newRepository := func(threshold algorithms.Threshold, keys ...interfaces.SigningKey) *repository.MultiSignableRepository[*MultiSignableModel] {
+		return repository.NewMultiSignableRepository[*MultiSignableModel](
+			store,
+			true,
+			true,
+			examples.NewNoncer(),
+			threshold,
+			keys,
+			verificationKeyStore,
+		)
+	}
+
+	record := &MultiSignableModel{Foo: "approved"}
+	if err := newRepository(twoOfThree, keys[0]).CreateVersion(ctx, record); !errors.Is(err, algorithms.ErrThresholdNotMet) {
+		return fmt.Errorf("expected a single signer to be refused: %v", err)
+	}
+
+	if record.GetId() != "" {
+		return fmt.Errorf("unexpected id after refusal: %s", record.GetId())
+	}
+
+	if err := newRepository(weighted, keys[0]).CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	// readers enforce their own threshold, whatever the writer's was
+	if err := newRepository(weighted).GetById(ctx, &MultiSignableModel{}, record.GetId()); err != nil {
+		return err
+	}
+
+	if err := newRepository(twoOfThree).GetById(ctx, &MultiSignableModel{}, record.GetId()); !errors.Is(err, algorithms.ErrThresholdNotMet) {
+		return fmt.Errorf("expected a single signature to fail two of three: %v", err)
+	}
+
+	if err := newRepository(weighted, keys[1], keys[2]).CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	if err := newRepository(twoOfThree).GetById(ctx, &MultiSignableModel{}, record.GetId()); err != nil {
+		return err
+	}
+
+	// dropping a signature from the stored record leaves it short of the threshold
+	loaded := &MultiSignableModel{}
+	if err := newRepository(weighted).GetById(ctx, loaded, record.GetId()); err != nil {
+		return err
+	}
+
+	tampered := primitives.StringList(loaded.Signatures[:1])
+	if _, err := store.Sql(ctx).ExecContext(ctx, `UPDATE multisignable SET signatures = ? WHERE id = ?`, tampered, record.GetId()); err != nil {
+		return err
+	}
+
+	if err := newRepository(weighted).GetById(ctx, &MultiSignableModel{}, record.GetId()); !errors.Is(err, algorithms.ErrThresholdNotMet) {
+		return fmt.Errorf("expected a dropped signature to fail the threshold: %v", err)
+	}
+
+	// repeating the remaining signature doesn't make up the weight
+	tampered = append(tampered, tampered[0])
+	if _, err := store.Sql(ctx).ExecContext(ctx, `UPDATE multisignable SET signatures = ? WHERE id = ?`, tampered, record.GetId()); err != nil {
+		return err
+	}
+
+	if err := newRepository(weighted).GetById(ctx, &MultiSignableModel{}, record.GetId()); !errors.Is(err, algorithms.ErrInvalidSignature) {
+		return fmt.Errorf("expected a repeated signature to be rejected: %v", err)
+	}
+
+	return nil
+}
+
+func TestKeyValidity(t *testing.T) {
+	if err := testKeyValidity(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testKeyValidity() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Sql(ctx).ExecContext(ctx, SIGNABLE_TABLE_SQL); err != nil {
+		return err
+	}
+
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	identity, err := key.Identity()
+	if err != nil {
+		return err
+	}
+
+	keyStore := examples.NewVerificationKeyStore()
+	keyStore.Add(identity, key)
+
+	r := repository.NewSignableRepository[*SignableModel](store, true, true, examples.NewNoncer(), key, keyStore)
+
+	record := &SignableModel{Foo: "before revocation"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	revokedAt := time.Now()
+	keyStore.Revoke(identity, revokedAt)
+	time.Sleep(5 * time.Millisecond)
+
+	// a leaked key can still write, but nothing it signs after the revocation reads back
+	record.Foo = "after revocation"
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	if err := r.GetBySequenceNumber(ctx, &SignableModel{}, record.GetPrefix(), 0); err != nil {
+		return err
+	}
+
+	if err := r.GetLatestByPrefix(ctx, &SignableModel{}, record.GetPrefix()); !errors.Is(err, algorithms.ErrKeyNotValid) {
+		return fmt.Errorf("expected a record signed after revocation to be rejected: %v", err)
+	}
+
+	// revoking again later doesn't reopen the window
+	keyStore.Revoke(identity, revokedAt.Add(time.Hour))
+
+	if err := r.GetLatestByPrefix(ctx, &SignableModel{}, record.GetPrefix()); !errors.Is(err, algorithms.ErrKeyNotValid) {
+		return fmt.Errorf("expected the revocation to stand: %v", err)
+	}
+
+	// nor can a leaked key backdate a new version into the window, since no version may claim to be
+	// older than the one it follows
+	latest := &SignableModel{}
+	if err := r.GetLatestByPrefix(repository.WithVerificationPolicy(ctx, repository.VerifyOff), latest, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	backdated := primitives.Timestamp(revokedAt.Add(-time.Hour))
+	latest.Foo = "backdated"
+	latest.StampCreatedAt(&backdated)
+
+	backdater := repository.NewSignableRepository[*SignableModel](store, true, false, examples.NewNoncer(), key, keyStore)
+	if err := backdater.CreateVersion(ctx, latest); !errors.Is(err, repository.ErrBackdated) {
+		return fmt.Errorf("expected a backdated version to be refused: %v", err)
+	}
+
+	// written to the table directly, it verifies on its own but not as part of the chain
+	preparer := repository.NewSignableRepository[*SignableModel](store, false, false, examples.NewNoncer(), key, keyStore)
+	if err := preparer.CreateVersion(ctx, latest); err != nil {
+		return err
+	}
+
+	columns := []string{"id", "prefix", "previous", "sequence_number", "created_at", "nonce", "signing_identity", "signature", "foo", "bar"}
+	if _, err := store.Sql(ctx).NamedExecContext(ctx, store.Dialect().Insert("signable", columns), latest); err != nil {
+		return err
+	}
+
+	report, err := r.VerifyChain(ctx, record.GetPrefix())
+	if err != nil {
+		return err
+	}
+
+	if len(report.Backdated) != 1 || report.Backdated[0] != latest.GetId() || report.Intact() {
+		return fmt.Errorf("expected the backdated version to be reported: %+v", report)
+	}
+
+	// without a timestamp there's no telling whether a record predates the revocation
+	untimestamped, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Apply[*SignableModel](ctx, untimestamped, untimestamped.Dialect(), schema.Options{Nonce: true}); err != nil {
+		return err
+	}
+
+	u := repository.NewSignableRepository[*SignableModel](untimestamped, true, false, examples.NewNoncer(), key, keyStore)
+
+	record = &SignableModel{Foo: "undated"}
+	if err := u.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	if err := u.GetById(ctx, &SignableModel{}, record.GetId()); !errors.Is(err, algorithms.ErrKeyNotValid) {
+		return fmt.Errorf("expected an untimestamped record to fail closed: %v", err)
+	}
+
+	return nil
+}
@@ -32,6 +32,8 @@ func NewSignableRepository[T primitives.SignableAndRecordable](
 
 			write:     write,
 			timestamp: timestamp,
+
+			cursorKey: newCursorKey(),
 		},
 
 		signingKey:           signingKey,
@@ -40,17 +42,11 @@ func NewSignableRepository[T primitives.SignableAndRecordable](
 }
 
 func (r SignableRepository[T]) CreateVersion(ctx context.Context, record T) error {
-	if err := r.prepareSignedRecord(record); err != nil {
-		return err
-	}
-
-	if r.write {
-		if err := r.insertRecord(ctx, record); err != nil {
-			return err
-		}
-	}
+	return r.createVersion(ctx, record, r.prepareSignedRecord, r.GetLatestByPrefix)
+}
 
-	return nil
+func (r SignableRepository[T]) UpdateWithRetry(ctx context.Context, prefix string, mutate func(T) error) (T, error) {
+	return r.updateWithRetry(ctx, prefix, mutate, r.CreateVersion, r.GetLatestByPrefix)
 }
 
 func (r SignableRepository[T]) GetById(ctx context.Context, record T, id string) error {
@@ -58,7 +54,7 @@ func (r SignableRepository[T]) GetById(ctx context.Context, record T, id string)
 		return err
 	}
 
-	if err := r.verifySignedRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifySignedRecord); err != nil {
 		return err
 	}
 
@@ -70,7 +66,7 @@ func (r SignableRepository[T]) GetBySequenceNumber(ctx context.Context, record T
 		return err
 	}
 
-	if err := r.verifySignedRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifySignedRecord); err != nil {
 		return err
 	}
 
@@ -82,7 +78,7 @@ func (r SignableRepository[T]) GetLatestByPrefix(ctx context.Context, record T,
 		return err
 	}
 
-	if err := r.verifySignedRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifySignedRecord); err != nil {
 		return err
 	}
 
@@ -94,13 +90,7 @@ func (r SignableRepository[T]) ListByPrefix(ctx context.Context, records *[]T, p
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifySignedRecord(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.verifyRecords(ctx, records, r.verifySignedRecord)
 }
 
 func (r SignableRepository[T]) Get(
@@ -113,7 +103,7 @@ func (r SignableRepository[T]) Get(
 		return err
 	}
 
-	if err := r.verifySignedRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifySignedRecord); err != nil {
 		return err
 	}
 
@@ -131,13 +121,7 @@ func (r SignableRepository[T]) Select(
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifySignedRecord(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.verifyRecords(ctx, records, r.verifySignedRecord)
 }
 
 func (r SignableRepository[T]) ListLatestByPrefix(
@@ -152,19 +136,13 @@ func (r SignableRepository[T]) ListLatestByPrefix(
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifySignedRecord(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.verifyRecords(ctx, records, r.verifySignedRecord)
 }
 
 // helpers
 
 func (r SignableRepository[T]) prepareSignedRecord(record T) error {
-	if err := algorithms.Sign(record, r.signingKey, func() error {
+	if err := algorithms.Sign(record, r.signingKey, r.canonicalizer, func() error {
 		return r.prepareVerifiableRecord(record)
 	}); err != nil {
 		return err
@@ -173,14 +151,61 @@ func (r SignableRepository[T]) prepareSignedRecord(record T) error {
 	return nil
 }
 
-func (r SignableRepository[T]) verifySignedRecord(record T) error {
-	if err := algorithms.VerifySignature(record, r.verificationKeyStore); err != nil {
+func (r SignableRepository[T]) verifySignedRecord(ctx context.Context, record T) error {
+	if err := algorithms.VerifySignatureContext(ctx, record, r.verificationKeyStore, r.canonicalizer); err != nil {
+		return err
+	}
+
+	if err := r.verifyRecord(ctx, record); err != nil {
 		return err
 	}
 
-	if err := r.verifyRecord(record); err != nil {
+	if err := r.verifyController(ctx, record); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// an identity that outlives its keys controls the chains it starts, so a version signed under any
+// other identity is rejected however valid its signature. with plain keys, any trusted key may
+// extend any chain, as before.
+func (r SignableRepository[T]) verifyController(ctx context.Context, record T) error {
+	if _, ok := r.verificationKeyStore.(interfaces.HistoricalVerificationKeyStore); !ok {
+		return nil
+	}
+
+	scope := verificationScopeFrom(ctx)
+
+	// the first version has just been verified, and its prefix commits to its signing identity
+	if record.GetSequenceNumber() == 0 {
+		scope.rememberController(record.GetPrefix(), record.GetSigningIdentity())
+		return nil
+	}
+
+	controller, ok := scope.controller(record.GetPrefix())
+	if !ok {
+		first := r.newRecord()
+		if err := r.getRecordBySequenceNumber(ctx, first, record.GetPrefix(), 0); err != nil {
+			return err
+		}
+
+		if err := r.verifyRecord(ctx, first); err != nil {
+			return err
+		}
+
+		controller = first.GetSigningIdentity()
+		scope.rememberController(record.GetPrefix(), controller)
+	}
+
+	if record.GetSigningIdentity() != controller {
+		return ControllerError{
+			Prefix:          record.GetPrefix(),
+			SequenceNumber:  record.GetSequenceNumber(),
+			Controller:      controller,
+			SigningIdentity: record.GetSigningIdentity(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"iter"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+)
+
+// the streaming variants hold a connection open while iterating, and verify each record as it is
+// yielded. a record that fails verification is yielded alongside its error, and iteration continues
+// if the caller does. any other error ends iteration. VerifyOff is the only policy that applies.
+//
+// since the loop holds a connection, using the same store inside it needs a second one. on a store
+// limited to a single connection, such as the in-memory sqlite store, that deadlocks. verifying
+// against a key store that reads the store (kel.KeyEventLog) uses it too, so iterate inside
+// data.WithTransaction, where every read shares the transaction's connection.
+
+func (r VerifiableRepository[T]) SelectIter(
+	ctx context.Context,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	query, values := r.selectQuery(condition)
+	return r.stream(ctx, r.finishQuery(query, order, limit), values, r.verifyRecord)
+}
+
+func (r VerifiableRepository[T]) ListByPrefixIter(ctx context.Context, prefix string) iter.Seq2[T, error] {
+	return r.SelectIter(ctx, expressions.Equal("prefix", prefix), orderings.Ascending("sequence_number"), nil)
+}
+
+func (r VerifiableRepository[T]) ListLatestByPrefixIter(
+	ctx context.Context,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	return r.streamLatestByPrefix(ctx, preFilter, condition, order, limit, r.verifyRecord)
+}
+
+func (r SignableRepository[T]) SelectIter(
+	ctx context.Context,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	query, values := r.selectQuery(condition)
+	return r.stream(ctx, r.finishQuery(query, order, limit), values, r.verifySignedRecord)
+}
+
+func (r SignableRepository[T]) ListByPrefixIter(ctx context.Context, prefix string) iter.Seq2[T, error] {
+	return r.SelectIter(ctx, expressions.Equal("prefix", prefix), orderings.Ascending("sequence_number"), nil)
+}
+
+func (r SignableRepository[T]) ListLatestByPrefixIter(
+	ctx context.Context,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	return r.streamLatestByPrefix(ctx, preFilter, condition, order, limit, r.verifySignedRecord)
+}
+
+func (r MultiSignableRepository[T]) SelectIter(
+	ctx context.Context,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	query, values := r.selectQuery(condition)
+	return r.stream(ctx, r.finishQuery(query, order, limit), values, r.verifyMultiSignedRecord)
+}
+
+func (r MultiSignableRepository[T]) ListByPrefixIter(ctx context.Context, prefix string) iter.Seq2[T, error] {
+	return r.SelectIter(ctx, expressions.Equal("prefix", prefix), orderings.Ascending("sequence_number"), nil)
+}
+
+func (r MultiSignableRepository[T]) ListLatestByPrefixIter(
+	ctx context.Context,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+) iter.Seq2[T, error] {
+	return r.streamLatestByPrefix(ctx, preFilter, condition, order, limit, r.verifyMultiSignedRecord)
+}
+
+// helpers
+
+func (r VerifiableRepository[T]) streamLatestByPrefix(
+	ctx context.Context,
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+	order data.Ordering,
+	limit *uint,
+	verify func(context.Context, T) error,
+) iter.Seq2[T, error] {
+	query, values, err := r.latestByPrefixQuery(preFilter, condition)
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+
+	return r.stream(ctx, r.finishQuery(query, order, limit), values, verify)
+}
+
+func (r VerifiableRepository[T]) stream(ctx context.Context, query string, values []any, verify func(context.Context, T) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := r.store.Sql(ctx).QueryRowsContext(ctx, query, values...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		scoped := withVerificationScope(ctx)
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			record := r.newRecord()
+			if err := rows.StructScan(record); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if !yield(record, r.verify(scoped, record, verify)) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
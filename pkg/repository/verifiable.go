@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
@@ -13,8 +17,12 @@ import (
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
 )
 
+// the number of times UpdateWithRetry will attempt to create a version before giving up
+const updateAttempts = 5
+
 type VerifiableRepository[T primitives.VerifiableAndRecordable] struct {
 	store  data.Store
 	noncer interfaces.Noncer
@@ -22,6 +30,14 @@ type VerifiableRepository[T primitives.VerifiableAndRecordable] struct {
 	// enable writes (disabled for admin dry-run commands for instance)
 	write     bool
 	timestamp bool
+
+	cursorKey []byte
+	policy    VerificationPolicy
+	workers   int
+	hasher    interfaces.Hasher
+	hashers   []interfaces.Hasher // accepted when verifying, alongside blake3
+
+	canonicalizer interfaces.Canonicalizer
 }
 
 // pass a nil noncer to omit nonces
@@ -37,21 +53,41 @@ func NewVerifiableRepository[T primitives.VerifiableAndRecordable](
 
 		write:     write,
 		timestamp: timestamp,
+
+		cursorKey: newCursorKey(),
 	}
 }
 
-func (r VerifiableRepository[T]) CreateVersion(ctx context.Context, record T) error {
-	if err := r.prepareVerifiableRecord(record); err != nil {
-		return err
-	}
+// new versions are addressed with this hasher (blake3 by default). this repository also accepts it
+// when verifying, and records addressed with a hasher it accepted before continue to verify.
+func (r *VerifiableRepository[T]) SetHasher(hasher interfaces.Hasher) {
+	r.AcceptHashers(hasher)
+	r.hasher = hasher
+}
 
-	if r.write {
-		if err := r.insertRecord(ctx, record); err != nil {
-			return err
-		}
+// records addressed with these hashers verify in this repository, as well as those addressed with
+// blake3. a hasher replaces any accepted earlier with the same code.
+func (r *VerifiableRepository[T]) AcceptHashers(hashers ...interfaces.Hasher) {
+	for _, hasher := range hashers {
+		r.hashers = slices.DeleteFunc(r.hashers, func(accepted interfaces.Hasher) bool {
+			return accepted.Code() == hasher.Code()
+		})
+		r.hashers = append(r.hashers, hasher)
 	}
+}
 
-	return nil
+// the serialisation records are hashed and signed over (algorithms.GoJSON by default). unlike the
+// hasher it isn't recorded in the data, so it must stay the same for the life of a table.
+func (r *VerifiableRepository[T]) SetCanonicalizer(canonicalizer interfaces.Canonicalizer) {
+	r.canonicalizer = canonicalizer
+}
+
+func (r VerifiableRepository[T]) CreateVersion(ctx context.Context, record T) error {
+	return r.createVersion(ctx, record, r.prepareVerifiableRecord, r.GetLatestByPrefix)
+}
+
+func (r VerifiableRepository[T]) UpdateWithRetry(ctx context.Context, prefix string, mutate func(T) error) (T, error) {
+	return r.updateWithRetry(ctx, prefix, mutate, r.CreateVersion, r.GetLatestByPrefix)
 }
 
 func (r VerifiableRepository[T]) GetById(ctx context.Context, record T, id string) error {
@@ -59,7 +95,7 @@ func (r VerifiableRepository[T]) GetById(ctx context.Context, record T, id strin
 		return err
 	}
 
-	if err := r.verifyRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifyRecord); err != nil {
 		return err
 	}
 
@@ -71,7 +107,7 @@ func (r VerifiableRepository[T]) GetBySequenceNumber(ctx context.Context, record
 		return err
 	}
 
-	if err := r.verifyRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifyRecord); err != nil {
 		return err
 	}
 
@@ -83,7 +119,7 @@ func (r VerifiableRepository[T]) GetLatestByPrefix(ctx context.Context, record T
 		return err
 	}
 
-	if err := r.verifyRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifyRecord); err != nil {
 		return err
 	}
 
@@ -95,13 +131,7 @@ func (r VerifiableRepository[T]) ListByPrefix(ctx context.Context, records *[]T,
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifyRecord(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.verifyRecords(ctx, records, r.verifyRecord)
 }
 
 func (r VerifiableRepository[T]) Get(
@@ -114,7 +144,7 @@ func (r VerifiableRepository[T]) Get(
 		return err
 	}
 
-	if err := r.verifyRecord(record); err != nil {
+	if err := r.verify(ctx, record, r.verifyRecord); err != nil {
 		return err
 	}
 
@@ -132,13 +162,7 @@ func (r VerifiableRepository[T]) Select(
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifyRecord(record); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return r.verifyRecords(ctx, records, r.verifyRecord)
 }
 
 func (r VerifiableRepository[T]) ListLatestByPrefix(
@@ -153,8 +177,60 @@ func (r VerifiableRepository[T]) ListLatestByPrefix(
 		return err
 	}
 
-	for _, record := range *records {
-		if err := r.verifyRecord(record); err != nil {
+	return r.verifyRecords(ctx, records, r.verifyRecord)
+}
+
+// the options needed to generate a table for this repository's configuration
+func (r VerifiableRepository[T]) SchemaOptions() schema.Options {
+	return schema.Options{
+		Nonce:     r.noncer != nil,
+		Timestamp: r.timestamp,
+	}
+}
+
+// helpers
+
+// on failure the record is restored to the state it was passed in with
+func (r VerifiableRepository[T]) createVersion(
+	ctx context.Context,
+	record T,
+	prepare func(T) error,
+	getLatest func(context.Context, T, string) error,
+) error {
+	restore := r.snapshot(record)
+
+	if err := prepare(record); err != nil {
+		restore()
+		return err
+	}
+
+	if r.write {
+		if err := r.checkCreatedAt(ctx, record); err != nil {
+			restore()
+			return err
+		}
+
+		if err := r.insertVersion(ctx, record); err != nil {
+			id := record.GetId()
+			restore()
+
+			var conflict SequenceConflictError
+			if errors.As(err, &conflict) {
+				// the unique constraint on the sequence number can fire before the primary key's
+				existing := r.newRecord()
+				if err := r.getRecordBySequenceNumber(ctx, existing, conflict.Prefix, uint(conflict.SequenceNumber)); err == nil && existing.GetId() == id {
+					return DuplicateRecordError{Id: id, Err: conflict.Err}
+				}
+
+				latest := r.newRecord()
+				if err := getLatest(strictly(ctx), latest, conflict.Prefix); err == nil {
+					return VersionConflictError[T]{
+						SequenceConflictError: conflict,
+						Latest:                latest,
+					}
+				}
+			}
+
 			return err
 		}
 	}
@@ -162,7 +238,57 @@ func (r VerifiableRepository[T]) ListLatestByPrefix(
 	return nil
 }
 
-// helpers
+func (r VerifiableRepository[T]) updateWithRetry(
+	ctx context.Context,
+	prefix string,
+	mutate func(T) error,
+	create func(context.Context, T) error,
+	getLatest func(context.Context, T, string) error,
+) (T, error) {
+	var zero T
+
+	record := r.newRecord()
+	if err := getLatest(strictly(ctx), record, prefix); err != nil {
+		return zero, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := mutate(record); err != nil {
+			return zero, err
+		}
+
+		err := create(ctx, record)
+		if err == nil {
+			return record, nil
+		}
+
+		if attempt >= updateAttempts {
+			return zero, err
+		}
+
+		var conflict VersionConflictError[T]
+		if !errors.As(err, &conflict) {
+			return zero, err
+		}
+
+		record = conflict.Latest
+	}
+}
+
+func (r VerifiableRepository[T]) newRecord() T {
+	return reflect.New(reflect.TypeFor[T]().Elem()).Interface().(T)
+}
+
+// a shallow copy suffices, since preparation replaces pointer fields rather than writing through them
+func (r VerifiableRepository[T]) snapshot(record T) func() {
+	value := reflect.ValueOf(record).Elem()
+	saved := reflect.New(value.Type()).Elem()
+	saved.Set(value)
+
+	return func() {
+		value.Set(saved)
+	}
+}
 
 func (r VerifiableRepository[T]) prepareVerifiableRecord(record T) error {
 	firstRecord := false
@@ -186,11 +312,11 @@ func (r VerifiableRepository[T]) prepareVerifiableRecord(record T) error {
 	}
 
 	if firstRecord {
-		if err := algorithms.CreatePrefix(record); err != nil {
+		if err := algorithms.CreatePrefix(record, r.hasher, r.canonicalizer); err != nil {
 			return err
 		}
 	} else {
-		if err := algorithms.SelfAddress(record); err != nil {
+		if err := algorithms.SelfAddress(record, r.hasher, r.canonicalizer); err != nil {
 			return err
 		}
 	}
@@ -198,13 +324,13 @@ func (r VerifiableRepository[T]) prepareVerifiableRecord(record T) error {
 	return nil
 }
 
-func (r VerifiableRepository[T]) verifyRecord(record T) error {
+func (r VerifiableRepository[T]) verifyRecord(ctx context.Context, record T) error {
 	if record.GetSequenceNumber() == 0 {
-		if err := algorithms.VerifyPrefixAndData(record); err != nil {
+		if err := algorithms.VerifyPrefixAndData(record, r.canonicalizer, r.hashers...); err != nil {
 			return err
 		}
 	} else {
-		if err := algorithms.VerifyAddressAndData(record); err != nil {
+		if err := algorithms.VerifyAddressAndData(record, r.canonicalizer, r.hashers...); err != nil {
 			return err
 		}
 	}
@@ -212,22 +338,86 @@ func (r VerifiableRepository[T]) verifyRecord(record T) error {
 	return nil
 }
 
+// signing keys are judged by the created_at a record claims, which its writer chooses, so a version
+// may not claim to be older than the version it follows
+func (r VerifiableRepository[T]) checkCreatedAt(ctx context.Context, record T) error {
+	if record.GetSequenceNumber() == 0 || record.GetPrevious() == nil || primitives.CreatedAt(record) == nil {
+		return nil
+	}
+
+	predecessor := r.newRecord()
+	if err := r.getRecordById(ctx, predecessor, *record.GetPrevious()); err != nil {
+		// without a stored predecessor there is nothing to be older than
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return checkCreatedAfter(record, predecessor)
+}
+
+func checkCreatedAfter[T primitives.VerifiableAndRecordable](record, predecessor T) error {
+	if createdBefore(record, predecessor) {
+		return fmt.Errorf(
+			"%w: %s/%d at %s",
+			ErrBackdated,
+			record.GetPrefix(),
+			record.GetSequenceNumber(),
+			primitives.CreatedAt(record).Format(primitives.ConsistentMilli),
+		)
+	}
+
+	return nil
+}
+
+// compared to the millisecond, which is all that is stored
+func createdBefore[T primitives.VerifiableAndRecordable](record, predecessor T) bool {
+	createdAt, predecessorCreatedAt := primitives.CreatedAt(record), primitives.CreatedAt(predecessor)
+	if createdAt == nil || predecessorCreatedAt == nil {
+		return false
+	}
+
+	return createdAt.Truncate(time.Millisecond).Before(predecessorCreatedAt.Truncate(time.Millisecond))
+}
+
 // sql helpers
 
-func (r VerifiableRepository[T]) insertRecord(ctx context.Context, record T) error {
-	fieldNames := r.getFieldNames(record)
-	innerFields := strings.Join(fieldNames, ", ")
-	innerValues := strings.Join(fieldNames, ", :")
+// inside a transaction the insert runs in a savepoint. a failed statement aborts a postgres
+// transaction, so the savepoint is rolled back before a conflict is inspected
+func (r VerifiableRepository[T]) insertVersion(ctx context.Context, record T) error {
+	if _, ok := data.TransactionFromContext(ctx, r.store); !ok {
+		return r.insertRecord(ctx, record)
+	}
 
+	return data.WithTransaction(ctx, r.store, func(ctx context.Context) error {
+		return r.insertRecord(ctx, record)
+	})
+}
+
+func (r VerifiableRepository[T]) insertRecord(ctx context.Context, record T) error {
 	// write to data store
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (:%s)", record.TableName(), innerFields, innerValues)
+	query := r.store.Dialect().Insert(record.TableName(), r.getFieldNames(record))
 
-	_, err := r.store.Sql().NamedExecContext(
+	_, err := r.store.Sql(ctx).NamedExecContext(
 		ctx,
 		query,
 		record,
 	)
 	if err != nil {
+		if r.store.IsPrimaryKeyViolation(err) {
+			return DuplicateRecordError{Id: record.GetId(), Err: err}
+		}
+
+		if r.store.IsUniqueViolation(err) {
+			return SequenceConflictError{
+				Prefix:         record.GetPrefix(),
+				SequenceNumber: record.GetSequenceNumber(),
+				Err:            err,
+			}
+		}
+
 		return err
 	}
 
@@ -259,17 +449,23 @@ func (r VerifiableRepository[T]) listRecordsByPrefix(ctx context.Context, record
 }
 
 func (r VerifiableRepository[T]) get(ctx context.Context, record T, condition data.ClauseOrExpression, order data.Ordering) error {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", (*new(T)).TableName(), condition.String())
+	dialect := r.store.Dialect()
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", r.tableName(), condition.String())
 
 	if order != nil {
 		query += fmt.Sprintf(" %s", order.String())
 	}
 
-	query += " LIMIT 1"
+	one := uint(1)
+	query += fmt.Sprintf(" %s", dialect.LimitAndOffset(&one, nil))
 
-	query = r.store.ReplacePlaceholders(query)
+	query = dialect.ReplacePlaceholders(query)
+
+	if err := r.store.Sql(ctx).GetContext(ctx, record, query, condition.Values()...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
 
-	if err := r.store.Sql().GetContext(ctx, record, query, condition.Values()...); err != nil {
 		return err
 	}
 
@@ -283,9 +479,9 @@ func (r VerifiableRepository[T]) _select(
 	order data.Ordering,
 	limit *uint,
 ) error {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", (*new(T)).TableName(), condition.String())
+	query, values := r.selectQuery(condition)
 
-	return r.selectCore(ctx, records, query, condition.Values(), order, limit)
+	return r.selectCore(ctx, records, query, values, order, limit)
 }
 
 func (r VerifiableRepository[T]) selectLatestByPrefix(
@@ -296,31 +492,9 @@ func (r VerifiableRepository[T]) selectLatestByPrefix(
 	order data.Ordering,
 	limit *uint,
 ) error {
-	if preFilter == nil {
-		return fmt.Errorf("for performance reasons, must supply a pre-filter")
-	}
-
-	values := []any{}
-	values = append(values, preFilter.Values()...)
-
-	innerQuery := fmt.Sprintf(
-		`SELECT ROW_NUMBER() OVER (PARTITION BY prefix ORDER BY sequence_number DESC) AS _rank, *
-			FROM %s
-			WHERE %s`,
-		(*new(T)).TableName(),
-		preFilter.String(),
-	)
-
-	query := fmt.Sprintf(
-		`WITH sequentialranks AS (%s) SELECT *
-			FROM sequentialranks
-			WHERE _rank=1`,
-		innerQuery,
-	)
-
-	if condition != nil {
-		query += fmt.Sprintf(" AND %s", condition.String())
-		values = append(values, condition.Values()...)
+	query, values, err := r.latestByPrefixQuery(preFilter, condition)
+	if err != nil {
+		return err
 	}
 
 	return r.selectCore(ctx, records, query, values, order, limit)
@@ -334,69 +508,66 @@ func (r VerifiableRepository[T]) selectCore(
 	order data.Ordering,
 	limit *uint,
 ) error {
-	if order != nil {
-		query += fmt.Sprintf(" %s", order.String())
-	}
-
-	if limit != nil {
-		query += fmt.Sprintf(" LIMIT %d", *limit)
-	}
-
-	query = r.store.ReplacePlaceholders(query)
+	query = r.finishQuery(query, order, limit)
 
-	if err := r.store.Sql().SelectContext(ctx, records, query, values...); err != nil {
+	if err := r.store.Sql(ctx).SelectContext(ctx, records, query, values...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// sql helper helpers
-
-func (r VerifiableRepository[T]) getFieldNames(s T) (fields []string) {
-	v := reflect.ValueOf(s)
-	t := v.Type()
-	return r.getLeafFieldNamesWithValues(t, v)
+func (r VerifiableRepository[T]) selectQuery(condition data.ClauseOrExpression) (string, []any) {
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s", r.tableName(), condition.String()), condition.Values()
 }
 
-func (r VerifiableRepository[T]) getLeafFieldNamesWithValues(t reflect.Type, v reflect.Value) []string {
-	if t.Kind() == reflect.Pointer {
-		t = t.Elem()
-		if v.Kind() == reflect.Pointer && !v.IsNil() {
-			v = v.Elem()
-		}
+func (r VerifiableRepository[T]) latestByPrefixQuery(
+	preFilter data.ClauseOrExpression,
+	condition data.ClauseOrExpression,
+) (string, []any, error) {
+	if preFilter == nil {
+		return "", nil, fmt.Errorf("for performance reasons, must supply a pre-filter")
 	}
 
-	if t.Kind() != reflect.Struct {
-		return []string{}
+	values := []any{}
+	values = append(values, preFilter.Values()...)
+
+	conditionString := ""
+	if condition != nil {
+		conditionString = condition.String()
+		values = append(values, condition.Values()...)
 	}
 
-	var names []string
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		fieldType := field.Type
-		fieldVal := v.Field(i)
+	query := r.store.Dialect().LatestByPrefix((*new(T)).TableName(), preFilter.String(), conditionString)
 
-		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(primitives.Timestamp{}) {
-			nested := r.getLeafFieldNamesWithValues(fieldType, fieldVal)
-			names = append(names, nested...)
-			continue
-		}
+	return query, values, nil
+}
 
-		tag := field.Tag.Get("db")
+func (r VerifiableRepository[T]) finishQuery(query string, order data.Ordering, limit *uint) string {
+	dialect := r.store.Dialect()
 
-		if strings.HasSuffix(tag, ",omitempty") && fieldVal.IsNil() {
-			continue
-		}
-		if tag == "-" {
-			continue
-		}
+	if order != nil {
+		query += fmt.Sprintf(" %s", order.String())
+	}
 
-		if tag == "" {
-			names = append(names, field.Name)
-		} else {
-			names = append(names, strings.TrimSuffix(tag, ",omitempty"))
-		}
+	if limit != nil {
+		query += fmt.Sprintf(" %s", dialect.LimitAndOffset(limit, nil))
 	}
+
+	return dialect.ReplacePlaceholders(query)
+}
+
+// sql helper helpers
+
+func (r VerifiableRepository[T]) tableName() string {
+	return r.store.Dialect().QuoteIdentifier((*new(T)).TableName())
+}
+
+func (r VerifiableRepository[T]) getFieldNames(s T) []string {
+	names := []string{}
+	for _, field := range schema.LeafFields(reflect.TypeOf(s), reflect.ValueOf(s)) {
+		names = append(names, field.Column)
+	}
+
 	return names
 }
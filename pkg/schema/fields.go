@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+type Field struct {
+	Column      string
+	JSON        string
+	Type        reflect.Type
+	OmitEmpty   bool
+	Bookkeeping bool          // declared by a primitives recorder rather than the model
+	Value       reflect.Value // only valid when walking a value
+}
+
+var primitivesPackage = reflect.TypeFor[primitives.VerifiableRecorder]().PkgPath()
+
+// walks nested and embedded structs, returning leaf fields in declaration order. if v is valid,
+// omitempty fields holding nil are skipped, mirroring what is written for a given record.
+func LeafFields(t reflect.Type, v reflect.Value) []Field {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if v.IsValid() && v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+	}
+
+	if t.Kind() != reflect.Struct {
+		return []Field{}
+	}
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := field.Type
+
+		var fieldVal reflect.Value
+		if v.IsValid() && v.Kind() == reflect.Struct {
+			fieldVal = v.Field(i)
+		}
+
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(primitives.Timestamp{}) {
+			nested := LeafFields(fieldType, fieldVal)
+			fields = append(fields, nested...)
+			continue
+		}
+
+		tag := field.Tag.Get("db")
+		omitEmpty := strings.HasSuffix(tag, ",omitempty")
+
+		if omitEmpty && fieldVal.IsValid() && nillable(fieldVal) && fieldVal.IsNil() {
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		column := strings.TrimSuffix(tag, ",omitempty")
+		if column == "" {
+			column = field.Name
+		}
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		fields = append(fields, Field{
+			Column:      column,
+			JSON:        jsonName,
+			Type:        fieldType,
+			OmitEmpty:   omitEmpty,
+			Bookkeeping: t.PkgPath() == primitivesPackage,
+			Value:       fieldVal,
+		})
+	}
+
+	return fields
+}
+
+// whether the generated column accepts NULL. nonce and created_at are optional in the struct but
+// always written once enabled.
+func (f Field) Nullable() bool {
+	switch f.Column {
+	case "id", "nonce", "created_at":
+		return false
+	default:
+		return f.Type.Kind() == reflect.Pointer || f.OmitEmpty
+	}
+}
+
+func nillable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return true
+	default:
+		return false
+	}
+}
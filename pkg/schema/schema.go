@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+// mirrors the nonce and timestamp configuration of the repository that will use the table. signing
+// columns are included whenever the record type is signable.
+type Options struct {
+	Nonce     bool
+	Timestamp bool
+}
+
+// returns the CREATE TABLE statement followed by any index statements
+func Generate[T primitives.VerifiableAndRecordable](dialect data.Dialect, options Options) ([]string, error) {
+	t := reflect.TypeFor[T]()
+	tableName := reflect.New(t.Elem()).Interface().(T).TableName()
+	_, signable := reflect.New(t.Elem()).Interface().(primitives.Signable)
+
+	lines := []string{}
+	for _, field := range LeafFields(t, reflect.Value{}) {
+		if field.Column == "nonce" && !options.Nonce {
+			continue
+		}
+		if field.Column == "created_at" && !options.Timestamp {
+			continue
+		}
+
+		line, err := columnDefinition(dialect, field)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, fmt.Sprintf(
+		"UNIQUE(%s, %s)",
+		dialect.QuoteIdentifier("prefix"),
+		dialect.QuoteIdentifier("sequence_number"),
+	))
+
+	statements := []string{
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (\n\t%s\n);",
+			dialect.QuoteIdentifier(tableName),
+			strings.Join(lines, ",\n\t"),
+		),
+	}
+
+	if options.Timestamp {
+		statements = append(statements, index(dialect, tableName, "created_at"))
+	}
+
+	if signable {
+		statements = append(statements, index(dialect, tableName, "signing_identity"))
+	}
+
+	return statements, nil
+}
+
+func Apply[T primitives.VerifiableAndRecordable](ctx context.Context, store data.Store, dialect data.Dialect, options Options) error {
+	statements, err := Generate[T](dialect, options)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		if _, err := store.Sql(ctx).ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func columnDefinition(dialect data.Dialect, field Field) (string, error) {
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	column := dialect.QuoteIdentifier(field.Column)
+
+	columnType, err := dialect.ColumnType(fieldType)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", field.Column, err)
+	}
+
+	switch {
+	case field.Column == "id":
+		return fmt.Sprintf("%s %s PRIMARY KEY", column, columnType), nil
+	case field.Nullable():
+		return fmt.Sprintf("%s %s", column, columnType), nil
+	default:
+		return fmt.Sprintf("%s %s NOT NULL", column, columnType), nil
+	}
+}
+
+func index(dialect data.Dialect, tableName, column string) string {
+	return fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s ON %s (%s);",
+		dialect.QuoteIdentifier(tableName+"_"+column),
+		dialect.QuoteIdentifier(tableName),
+		dialect.QuoteIdentifier(column),
+	)
+}
@@ -0,0 +1,162 @@
+package schema_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/dialects"
+	data "github.com/jasoncolburne/verifiable-storage-go/pkg/data/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/interfaces/examples"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/schema"
+)
+
+type Model struct {
+	primitives.SignableRecorder
+	Foo   string  `db:"foo" json:"foo"`
+	Count int     `db:"count" json:"count"`
+	Note  *string `db:"note" json:"note,omitempty"`
+}
+
+func (*Model) TableName() string {
+	return `model`
+}
+
+type DeterministicModel struct {
+	primitives.VerifiableRecorder
+	Foo string `db:"foo" json:"foo"`
+}
+
+func (*DeterministicModel) TableName() string {
+	return `deterministic`
+}
+
+func TestGenerate(t *testing.T) {
+	if err := testGenerate(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testGenerate() error {
+	statements, err := schema.Generate[*Model](dialects.Postgres(), schema.Options{Nonce: true, Timestamp: true})
+	if err != nil {
+		return err
+	}
+
+	expected := []string{
+		`CREATE TABLE IF NOT EXISTS "model" (
+	"id" TEXT PRIMARY KEY,
+	"prefix" TEXT NOT NULL,
+	"sequence_number" BIGINT NOT NULL,
+	"previous" TEXT,
+	"nonce" TEXT NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL,
+	"signing_identity" TEXT NOT NULL,
+	"signature" TEXT NOT NULL,
+	"foo" TEXT NOT NULL,
+	"count" BIGINT NOT NULL,
+	"note" TEXT,
+	UNIQUE("prefix", "sequence_number")
+);`,
+		`CREATE INDEX IF NOT EXISTS "model_created_at" ON "model" ("created_at");`,
+		`CREATE INDEX IF NOT EXISTS "model_signing_identity" ON "model" ("signing_identity");`,
+	}
+
+	if len(statements) != len(expected) {
+		return fmt.Errorf("unexpected statement count: %d", len(statements))
+	}
+
+	for i, statement := range statements {
+		if !strings.EqualFold(statement, expected[i]) {
+			return fmt.Errorf("unexpected statement %d: %s", i, statement)
+		}
+	}
+
+	statements, err = schema.Generate[*DeterministicModel](dialects.SQLite(), schema.Options{})
+	if err != nil {
+		return err
+	}
+
+	if len(statements) != 1 {
+		return fmt.Errorf("unexpected statement count: %d", len(statements))
+	}
+
+	if !strings.EqualFold(statements[0], `CREATE TABLE IF NOT EXISTS "deterministic" (
+	"id" TEXT PRIMARY KEY,
+	"prefix" TEXT NOT NULL,
+	"sequence_number" BIGINT NOT NULL,
+	"previous" TEXT,
+	"foo" TEXT NOT NULL,
+	UNIQUE("prefix", "sequence_number")
+);`) {
+		return fmt.Errorf("unexpected statement: %s", statements[0])
+	}
+
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	if err := testApply(); err != nil {
+		fmt.Printf("%s\n", err)
+		t.FailNow()
+	}
+}
+
+func testApply() error {
+	ctx := context.Background()
+
+	store, err := data.NewInMemorySQLiteStore()
+	if err != nil {
+		return err
+	}
+
+	key, err := examples.NewEd25519(nil)
+	if err != nil {
+		return err
+	}
+
+	identity, err := key.Identity()
+	if err != nil {
+		return err
+	}
+
+	verificationKeyStore := examples.NewVerificationKeyStore()
+	verificationKeyStore.Add(identity, key)
+
+	r := repository.NewSignableRepository[*Model](
+		store,
+		true,
+		true,
+		examples.NewNoncer(),
+		key,
+		verificationKeyStore,
+	)
+
+	if err := schema.Apply[*Model](ctx, store, dialects.SQLite(), r.SchemaOptions()); err != nil {
+		return err
+	}
+
+	record := &Model{Foo: "bar"}
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	if err := r.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	loaded := &Model{}
+	if err := r.GetLatestByPrefix(ctx, loaded, record.GetPrefix()); err != nil {
+		return err
+	}
+
+	if loaded.GetSequenceNumber() != 1 {
+		return fmt.Errorf("unexpected sequence number: %d", loaded.GetSequenceNumber())
+	}
+
+	return nil
+}